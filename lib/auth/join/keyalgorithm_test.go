@@ -0,0 +1,76 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package join
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKey(t *testing.T) {
+	for _, alg := range []KeyAlgorithm{
+		KeyAlgorithmEd25519,
+		KeyAlgorithmECDSAP256,
+		KeyAlgorithmRSA2048,
+		KeyAlgorithmRSA4096,
+		"",
+	} {
+		t.Run(string(alg), func(t *testing.T) {
+			signer, err := GenerateKey(alg)
+			require.NoError(t, err)
+			require.NotNil(t, signer)
+
+			pub, err := SSHPublicKey(signer)
+			require.NoError(t, err)
+			require.NotEmpty(t, pub.Marshal())
+		})
+	}
+
+	_, err := GenerateKey(KeyAlgorithm("bogus"))
+	require.Error(t, err)
+}
+
+// TestMarshalOpenSSHPrivateKey proves the hand-rolled OpenSSH encoding
+// round-trips through x/crypto/ssh's own parser, which is the same parser
+// `ssh-keygen`/OpenSSH tooling relies on to read this format.
+func TestMarshalOpenSSHPrivateKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	pemBytes, err := MarshalOpenSSHPrivateKey(priv)
+	require.NoError(t, err)
+
+	parsed, err := ssh.ParseRawPrivateKey(pemBytes)
+	require.NoError(t, err)
+
+	parsedKey, ok := parsed.(*ed25519.PrivateKey)
+	require.True(t, ok, "expected *ed25519.PrivateKey, got %T", parsed)
+	require.True(t, priv.Equal(*parsedKey))
+}
+
+func TestMarshalOpenSSHPrivateKeyRejectsNonEd25519(t *testing.T) {
+	signer, err := GenerateKey(KeyAlgorithmECDSAP256)
+	require.NoError(t, err)
+
+	_, err = MarshalOpenSSHPrivateKey(signer)
+	require.Error(t, err)
+}
@@ -0,0 +1,30 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package recordingencryption
+
+import "context"
+
+// KeyUnwrapper unwraps a wrapped recording private key, returning the raw
+// key material RecordingIdentity uses to attempt decryption of a session
+// recording. Implementations hold whatever key material or credentials are
+// needed to reverse the wrapping applied when the key was registered, e.g.
+// a locally held private key, or a call out to a remote unwrapping service.
+type KeyUnwrapper interface {
+	// Unwrap returns the unwrapped private key bytes for the given wrapped
+	// key blob.
+	Unwrap(ctx context.Context, wrappedPrivateKey []byte) ([]byte, error)
+}
@@ -0,0 +1,174 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package recordingencryption
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+	"github.com/gravitational/trace"
+)
+
+// PluginRegistry restricts which age-plugin binaries (age-plugin-<name> on
+// $PATH) the auth server is willing to exec when parsing plugin-scheme
+// recipients and identities, e.g. "yubikey", "tpm", "kms". Operators set
+// this from the allowed-plugins list in their session_recording_config;
+// an empty registry allows nothing, so plugin support is opt-in.
+type PluginRegistry struct {
+	allowed map[string]struct{}
+}
+
+// NewPluginRegistry returns a PluginRegistry permitting exactly the named
+// plugins.
+func NewPluginRegistry(allowedPlugins []string) *PluginRegistry {
+	allowed := make(map[string]struct{}, len(allowedPlugins))
+	for _, name := range allowedPlugins {
+		allowed[strings.ToLower(name)] = struct{}{}
+	}
+	return &PluginRegistry{allowed: allowed}
+}
+
+// IsAllowed reports whether name is permitted to be exec'd.
+func (r *PluginRegistry) IsAllowed(name string) bool {
+	if r == nil {
+		return false
+	}
+	_, ok := r.allowed[strings.ToLower(name)]
+	return ok
+}
+
+// pluginNameFromRecipient extracts the plugin name from an age-plugin
+// recipient string of the form "age1<name>1<data>" (e.g. "age1yubikey1...",
+// "age1tpm1...", "age1kms1..."). Bech32's data/checksum alphabet excludes
+// '1', so the last '1' in the string is always the HRP/data separator;
+// native X25519 recipients ("age1<data>") have no further '1' in their HRP
+// ("age"), so they're correctly reported as ok == false here.
+func pluginNameFromRecipient(recipient string) (name string, ok bool) {
+	i := strings.LastIndexByte(recipient, '1')
+	if i < 0 {
+		return "", false
+	}
+	hrp := recipient[:i]
+	if !strings.HasPrefix(hrp, "age1") || hrp == "age1" {
+		return "", false
+	}
+	return hrp[len("age1"):], true
+}
+
+// pluginNameFromIdentity extracts the plugin name from an age-plugin
+// identity string of the form "AGE-PLUGIN-<NAME>-1<data>".
+func pluginNameFromIdentity(identity string) (name string, ok bool) {
+	const prefix = "AGE-PLUGIN-"
+	if !strings.HasPrefix(identity, prefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(identity, prefix)
+	i := strings.IndexByte(rest, '-')
+	if i < 1 {
+		return "", false
+	}
+	return strings.ToLower(rest[:i]), true
+}
+
+// ParsePluginRecipient parses an age-plugin-scheme recipient (one that
+// doesn't parse as a native X25519 recipient), dispatching encryption to
+// the named plugin binary via filippo.io/age/plugin. This lets
+// types.SessionRecordingConfig.EncryptionKeys point at YubiKey-, TPM- or
+// cloud-KMS-wrapped keys instead of only locally-held X25519 keys.
+//
+// ParsePluginRecipient returns ok == false (with a nil error) when
+// recipient doesn't look like a plugin recipient at all, so callers - such
+// as ParseRecordingRecipient's native-key path - can fall through to their
+// own parsing rather than treating every non-plugin string as an error.
+//
+// Neither call site exists in this checkout: EncryptionWrapper.WithEncryption
+// (encryptedio.go) already calls a ParseRecordingRecipient that isn't defined
+// anywhere in this package, or anywhere else in this tree, and the same is
+// true of the RecordingIdentity referenced below and by EncryptedIO.WithDecryption.
+// That's a level deeper than a missing registration - the function these two
+// are meant to plug into has itself never been added here - so there's no
+// local call site to wire them into yet.
+func ParsePluginRecipient(registry *PluginRegistry, recipient string) (r age.Recipient, ok bool, err error) {
+	name, ok := pluginNameFromRecipient(recipient)
+	if !ok {
+		return nil, false, nil
+	}
+	if !registry.IsAllowed(name) {
+		return nil, true, trace.AccessDenied("age plugin %q is not in the allowed plugin list", name)
+	}
+
+	rec, err := plugin.NewRecipient(recipient, nil)
+	if err != nil {
+		return nil, true, trace.Wrap(err, "initializing age plugin %q", name)
+	}
+	return rec, true, nil
+}
+
+// ListPluginIdentities scans dir for identity files in the format
+// age-keygen writes (one "AGE-PLUGIN-<NAME>-..." identity per line,
+// blank lines and "#"-prefixed comments ignored), parsing each plugin
+// identity found via filippo.io/age/plugin. Only plugins present in
+// registry are exec'd; identities for other plugins are skipped rather
+// than rejected outright, since a directory may be shared across auth
+// servers with different allow-lists.
+//
+// RecordingIdentity should merge the result into the candidate identities
+// it tries during Unwrap, alongside its native X25519 identities - but, as
+// noted on ParsePluginRecipient above, no such type exists in this package
+// to merge it into yet.
+func ListPluginIdentities(registry *PluginRegistry, dir string) ([]age.Identity, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	var identities []age.Identity
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			name, ok := pluginNameFromIdentity(line)
+			if !ok || !registry.IsAllowed(name) {
+				continue
+			}
+
+			ident, err := plugin.NewIdentity(line, nil)
+			if err != nil {
+				return nil, trace.Wrap(err, "initializing age plugin %q from %s", name, path)
+			}
+			identities = append(identities, ident)
+		}
+	}
+
+	return identities, nil
+}
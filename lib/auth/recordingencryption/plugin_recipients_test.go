@@ -0,0 +1,133 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package recordingencryption
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginNameFromRecipient(t *testing.T) {
+	tests := []struct {
+		recipient string
+		wantName  string
+		wantOK    bool
+	}{
+		{"age1yubikey1qtn7ac088nzl5eq", "yubikey", true},
+		{"age1tpm1qqqqqqqqqqqqqqqqqqqq", "tpm", true},
+		{"age1kms1qqqqqqqqqqqqqqqqqqqq", "kms", true},
+		{"age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs", "", false},
+		{"not-a-recipient-at-all", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := pluginNameFromRecipient(tt.recipient)
+		require.Equal(t, tt.wantOK, ok, tt.recipient)
+		require.Equal(t, tt.wantName, name, tt.recipient)
+	}
+}
+
+func TestPluginNameFromIdentity(t *testing.T) {
+	tests := []struct {
+		identity string
+		wantName string
+		wantOK   bool
+	}{
+		{"AGE-PLUGIN-YUBIKEY-1QQQQQQQQQQQQQQQQQQQQQQQQQQ", "yubikey", true},
+		{"AGE-SECRET-KEY-1QQQQQQQQQQQQQQQQQQQQQQQQQQ", "", false},
+		{"AGE-PLUGIN-", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := pluginNameFromIdentity(tt.identity)
+		require.Equal(t, tt.wantOK, ok, tt.identity)
+		require.Equal(t, tt.wantName, name, tt.identity)
+	}
+}
+
+func TestPluginRegistry(t *testing.T) {
+	registry := NewPluginRegistry([]string{"YubiKey", "tpm"})
+	require.True(t, registry.IsAllowed("yubikey"))
+	require.True(t, registry.IsAllowed("TPM"))
+	require.False(t, registry.IsAllowed("kms"))
+
+	var nilRegistry *PluginRegistry
+	require.False(t, nilRegistry.IsAllowed("yubikey"))
+}
+
+func TestParsePluginRecipientNotAPlugin(t *testing.T) {
+	registry := NewPluginRegistry([]string{"yubikey"})
+
+	_, ok, err := ParsePluginRecipient(registry, "age1qyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqszqgpqyqs")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestParsePluginRecipientNotAllowed(t *testing.T) {
+	registry := NewPluginRegistry([]string{"tpm"})
+
+	_, ok, err := ParsePluginRecipient(registry, "age1yubikey1qtn7ac088nzl5eq")
+	require.True(t, ok)
+	require.True(t, trace.IsAccessDenied(err), "expected AccessDenied, got %v", err)
+}
+
+func TestListPluginIdentitiesSkipsDisallowedAndMalformed(t *testing.T) {
+	dir := t.TempDir()
+	contents := "# a comment\n\n" +
+		"AGE-PLUGIN-KMS-1QQQQQQQQQQQQQQQQQQQQQQQQQQ\n" +
+		"not-an-identity\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "identities"), []byte(contents), 0o600))
+
+	registry := NewPluginRegistry([]string{"yubikey"})
+	identities, err := ListPluginIdentities(registry, dir)
+	require.NoError(t, err)
+	require.Empty(t, identities, "the kms identity isn't in the registry and the other line isn't a plugin identity")
+}
+
+func TestListPluginIdentitiesMissingDir(t *testing.T) {
+	registry := NewPluginRegistry([]string{"yubikey"})
+	_, err := ListPluginIdentities(registry, filepath.Join(t.TempDir(), "does-not-exist"))
+	require.Error(t, err)
+}
+
+// TestParsePluginRecipientDispatchesToBinary proves that the age.Recipient
+// ParsePluginRecipient returns is a real plugin.Recipient, not a stand-in:
+// encrypting to it execs "age-plugin-<name>" on $PATH the same way the
+// backlog's round-trip test expected. There's no fake plugin binary on
+// $PATH here - building one that correctly speaks the age-plugin wire
+// protocol isn't something this test can do without the plugin package's
+// own source to check it against, which isn't vendored anywhere in this
+// checkout - so this asserts the one thing that's both true and verifiable
+// without that: age.Encrypt reaches all the way into exec.LookPath for the
+// plugin binary and fails with that binary's name in the error, rather than
+// silently succeeding or failing earlier in parsing.
+func TestParsePluginRecipientDispatchesToBinary(t *testing.T) {
+	registry := NewPluginRegistry([]string{"doesnotexist"})
+
+	recipient, ok, err := ParsePluginRecipient(registry, "age1doesnotexist1qtn7ac088nzl5eq")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var buf bytes.Buffer
+	_, err = age.Encrypt(&buf, recipient)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "age-plugin-doesnotexist")
+}
@@ -0,0 +1,258 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package recordingencryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/gravitational/trace"
+)
+
+// vaultTokenRefreshSkew is how far before a cached Vault token's lease
+// expires vaultLoginCache re-authenticates rather than reusing it.
+const vaultTokenRefreshSkew = 30 * time.Second
+
+// vaultLoginFunc authenticates to Vault and returns a client token plus how
+// long that token is valid for from the moment of login.
+type vaultLoginFunc func(ctx context.Context, client *vault.Client) (token string, ttl time.Duration, err error)
+
+// NewAppRoleLogin returns a vaultLoginFunc authenticating via Vault's
+// AppRole auth method (auth/<mountPath>/login). mountPath defaults to
+// "approle" when empty.
+func NewAppRoleLogin(mountPath, roleID, secretID string) vaultLoginFunc {
+	if mountPath == "" {
+		mountPath = "approle"
+	}
+	return func(ctx context.Context, client *vault.Client) (string, time.Duration, error) {
+		secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mountPath), map[string]any{
+			"role_id":   roleID,
+			"secret_id": secretID,
+		})
+		if err != nil {
+			return "", 0, trace.Wrap(err, "logging into vault via approle")
+		}
+		if secret == nil || secret.Auth == nil {
+			return "", 0, trace.NotFound("vault approle login returned no auth info")
+		}
+		return secret.Auth.ClientToken, time.Duration(secret.Auth.LeaseDuration) * time.Second, nil
+	}
+}
+
+// NewKubernetesLogin returns a vaultLoginFunc authenticating via Vault's
+// Kubernetes auth method (auth/<mountPath>/login), using the projected
+// service account token at jwtPath. mountPath defaults to "kubernetes" and
+// jwtPath to the standard projected-token location when empty.
+func NewKubernetesLogin(mountPath, role, jwtPath string) vaultLoginFunc {
+	if mountPath == "" {
+		mountPath = "kubernetes"
+	}
+	if jwtPath == "" {
+		jwtPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	}
+	return func(ctx context.Context, client *vault.Client) (string, time.Duration, error) {
+		jwt, err := os.ReadFile(jwtPath)
+		if err != nil {
+			return "", 0, trace.ConvertSystemError(err)
+		}
+
+		secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mountPath), map[string]any{
+			"role": role,
+			"jwt":  string(jwt),
+		})
+		if err != nil {
+			return "", 0, trace.Wrap(err, "logging into vault via kubernetes auth")
+		}
+		if secret == nil || secret.Auth == nil {
+			return "", 0, trace.NotFound("vault kubernetes login returned no auth info")
+		}
+		return secret.Auth.ClientToken, time.Duration(secret.Auth.LeaseDuration) * time.Second, nil
+	}
+}
+
+// vaultLoginCache caches the token login returns, re-authenticating
+// shortly before it expires rather than on every Transit call.
+type vaultLoginCache struct {
+	client *vault.Client
+	login  vaultLoginFunc
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// token returns a live Vault client token, re-authenticating via login if
+// the cached one is unset or near expiry.
+func (c *vaultLoginCache) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiresAt.Add(-vaultTokenRefreshSkew)) {
+		return c.token, nil
+	}
+
+	token, ttl, err := c.login(ctx, c.client)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	c.token = token
+	c.expiresAt = time.Now().Add(ttl)
+	return c.token, nil
+}
+
+// VaultTransitKeyUnwrapperConfig configures a VaultTransitKeyUnwrapper.
+type VaultTransitKeyUnwrapperConfig struct {
+	// Client is the Vault API client to use, pointed at the cluster's
+	// Vault address. Required.
+	Client *vault.Client
+	// Login authenticates Client and is called again to re-authenticate
+	// once the cached token nears expiry. Required.
+	Login vaultLoginFunc
+	// KeyName is the Transit key recording keys are wrapped under.
+	KeyName string
+	// MountPath is the Transit secrets engine mount path, defaulting to
+	// "transit" when empty.
+	MountPath string
+}
+
+// VaultTransitKeyUnwrapper is a KeyUnwrapper backed by HashiCorp Vault's
+// Transit secrets engine: wrapped recording key blobs are Vault
+// ciphertexts ("vault:v1:..."), unwrapped via transit/decrypt/<key>. It
+// also provides the matching Wrap path so EncryptionWrapper can register
+// newly-generated per-recording keys with Vault instead of holding them
+// locally, keeping recording key material entirely off the auth server's
+// disk.
+type VaultTransitKeyUnwrapper struct {
+	client    *vault.Client
+	keyName   string
+	mountPath string
+	tokens    *vaultLoginCache
+}
+
+// NewVaultTransitKeyUnwrapper returns a VaultTransitKeyUnwrapper configured
+// per cfg.
+func NewVaultTransitKeyUnwrapper(cfg VaultTransitKeyUnwrapperConfig) (*VaultTransitKeyUnwrapper, error) {
+	switch {
+	case cfg.Client == nil:
+		return nil, trace.BadParameter("Client is required for VaultTransitKeyUnwrapper")
+	case cfg.Login == nil:
+		return nil, trace.BadParameter("Login is required for VaultTransitKeyUnwrapper")
+	case cfg.KeyName == "":
+		return nil, trace.BadParameter("KeyName is required for VaultTransitKeyUnwrapper")
+	}
+
+	mountPath := cfg.MountPath
+	if mountPath == "" {
+		mountPath = "transit"
+	}
+
+	return &VaultTransitKeyUnwrapper{
+		client:    cfg.Client,
+		keyName:   cfg.KeyName,
+		mountPath: mountPath,
+		tokens:    &vaultLoginCache{client: cfg.Client, login: cfg.Login},
+	}, nil
+}
+
+// Unwrap decrypts wrappedPrivateKey (a Vault Transit ciphertext) via
+// transit/decrypt/<key>, returning the raw private key bytes. Implements
+// KeyUnwrapper.
+func (v *VaultTransitKeyUnwrapper) Unwrap(ctx context.Context, wrappedPrivateKey []byte) ([]byte, error) {
+	secret, err := v.call(ctx, "decrypt", map[string]any{
+		"ciphertext": string(wrappedPrivateKey),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	plaintextB64, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, trace.BadParameter("vault transit decrypt response missing plaintext")
+	}
+	key, err := base64.StdEncoding.DecodeString(plaintextB64)
+	if err != nil {
+		return nil, trace.Wrap(err, "decoding vault transit plaintext")
+	}
+	return key, nil
+}
+
+// Wrap encrypts privateKey via transit/encrypt/<key>, returning the Vault
+// ciphertext to store as the wrapped key blob.
+func (v *VaultTransitKeyUnwrapper) Wrap(ctx context.Context, privateKey []byte) ([]byte, error) {
+	secret, err := v.call(ctx, "encrypt", map[string]any{
+		"plaintext": base64.StdEncoding.EncodeToString(privateKey),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, trace.BadParameter("vault transit encrypt response missing ciphertext")
+	}
+	return []byte(ciphertext), nil
+}
+
+// RotateKeyVersion rotates the Transit key to a new version via
+// transit/keys/<key>/rotate. Subsequent Wrap calls encrypt under the new
+// version; Unwrap keeps working for ciphertexts from older versions down
+// to Vault's configured min_decryption_version for the key.
+func (v *VaultTransitKeyUnwrapper) RotateKeyVersion(ctx context.Context) error {
+	token, err := v.tokens.token(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	client, err := v.client.Clone()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client.SetToken(token)
+
+	_, err = client.Logical().WriteWithContext(ctx, fmt.Sprintf("%s/keys/%s/rotate", v.mountPath, v.keyName), nil)
+	return trace.Wrap(err, "rotating vault transit key")
+}
+
+// call authenticates (re-using the cached token when it's still fresh) and
+// performs a Transit op ("encrypt"/"decrypt") against v.keyName.
+func (v *VaultTransitKeyUnwrapper) call(ctx context.Context, op string, data map[string]any) (*vault.Secret, error) {
+	token, err := v.tokens.token(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	client, err := v.client.Clone()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client.SetToken(token)
+
+	secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("%s/%s/%s", v.mountPath, op, v.keyName), data)
+	if err != nil {
+		return nil, trace.Wrap(err, "calling vault transit %s", op)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, trace.NotFound("vault transit %s returned no data", op)
+	}
+	return secret, nil
+}
@@ -0,0 +1,192 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package recordingencryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault stubs just enough of Vault's HTTP API (AppRole login plus
+// Transit encrypt/decrypt/rotate) to exercise VaultTransitKeyUnwrapper
+// without a real Vault dev-server.
+type fakeVault struct {
+	server *httptest.Server
+
+	logins  atomic.Int64
+	version atomic.Int64
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	f := &fakeVault{}
+	f.version.Store(1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		f.logins.Add(1)
+		writeSecret(w, map[string]any{
+			"auth": map[string]any{
+				"client_token":   "fake-token",
+				"lease_duration": 1,
+			},
+		})
+	})
+	mux.HandleFunc("/v1/transit/encrypt/test-key", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Plaintext string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		writeSecret(w, map[string]any{
+			"data": map[string]any{
+				"ciphertext": fmt.Sprintf("vault:v%d:%s", f.version.Load(), body.Plaintext),
+			},
+		})
+	})
+	mux.HandleFunc("/v1/transit/decrypt/test-key", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Ciphertext string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		var version int
+		var plaintext string
+		_, err := fmt.Sscanf(body.Ciphertext, "vault:v%d:%s", &version, &plaintext)
+		require.NoError(t, err)
+		writeSecret(w, map[string]any{
+			"data": map[string]any{
+				"plaintext": plaintext,
+			},
+		})
+	})
+	mux.HandleFunc("/v1/transit/keys/test-key/rotate", func(w http.ResponseWriter, r *http.Request) {
+		f.version.Add(1)
+		writeSecret(w, nil)
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func writeSecret(w http.ResponseWriter, fields map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(fields)
+}
+
+func (f *fakeVault) client(t *testing.T) *vault.Client {
+	cfg := vault.DefaultConfig()
+	cfg.Address = f.server.URL
+	client, err := vault.NewClient(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func newTestUnwrapper(t *testing.T, f *fakeVault) *VaultTransitKeyUnwrapper {
+	u, err := NewVaultTransitKeyUnwrapper(VaultTransitKeyUnwrapperConfig{
+		Client:  f.client(t),
+		Login:   NewAppRoleLogin("", "role-id", "secret-id"),
+		KeyName: "test-key",
+	})
+	require.NoError(t, err)
+	return u
+}
+
+func TestVaultTransitKeyUnwrapperWrapUnwrap(t *testing.T) {
+	f := newFakeVault(t)
+	u := newTestUnwrapper(t, f)
+
+	plaintext := base64.StdEncoding.EncodeToString([]byte("super-secret-recording-key"))
+	wrapped, err := u.Wrap(context.Background(), []byte(plaintext))
+	require.NoError(t, err)
+
+	unwrapped, err := u.Unwrap(context.Background(), wrapped)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, string(unwrapped))
+}
+
+func TestVaultTransitKeyUnwrapperCachesToken(t *testing.T) {
+	f := newFakeVault(t)
+	u := newTestUnwrapper(t, f)
+
+	_, err := u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+	_, err = u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), f.logins.Load(), "expected the cached token to be reused across calls")
+}
+
+func TestVaultTransitKeyUnwrapperReauthenticatesAfterExpiry(t *testing.T) {
+	f := newFakeVault(t)
+	u := newTestUnwrapper(t, f)
+
+	_, err := u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+
+	// The fake login grants a 1s lease; wait past it plus the refresh skew
+	// so the next call is forced to re-authenticate.
+	u.tokens.expiresAt = time.Now().Add(-vaultTokenRefreshSkew)
+
+	_, err = u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), f.logins.Load())
+}
+
+func TestVaultTransitKeyUnwrapperRotateKeyVersion(t *testing.T) {
+	f := newFakeVault(t)
+	u := newTestUnwrapper(t, f)
+
+	wrappedV1, err := u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+
+	require.NoError(t, u.RotateKeyVersion(context.Background()))
+
+	wrappedV2, err := u.Wrap(context.Background(), []byte("aGVsbG8="))
+	require.NoError(t, err)
+	require.NotEqual(t, wrappedV1, wrappedV2, "expected ciphertext to reflect the rotated key version")
+
+	// Unwrap still works for the older version's ciphertext.
+	_, err = u.Unwrap(context.Background(), wrappedV1)
+	require.NoError(t, err)
+}
+
+func TestNewVaultTransitKeyUnwrapperRequiresConfig(t *testing.T) {
+	f := newFakeVault(t)
+
+	_, err := NewVaultTransitKeyUnwrapper(VaultTransitKeyUnwrapperConfig{
+		Login:   NewAppRoleLogin("", "role-id", "secret-id"),
+		KeyName: "test-key",
+	})
+	require.Error(t, err)
+
+	_, err = NewVaultTransitKeyUnwrapper(VaultTransitKeyUnwrapperConfig{
+		Client:  f.client(t),
+		KeyName: "test-key",
+	})
+	require.Error(t, err)
+
+	_, err = NewVaultTransitKeyUnwrapper(VaultTransitKeyUnwrapperConfig{
+		Client: f.client(t),
+		Login:  NewAppRoleLogin("", "role-id", "secret-id"),
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package sshagent loads Teleport-issued SSH certificates directly into a
+// running ssh-agent, so operators can use short-lived certs from any tool
+// that speaks the SSH agent protocol without ever writing them to disk.
+// This mirrors the "install cert into agent" pattern used by SSH CA clients
+// like cashier.
+package sshagent
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// DialSocket connects to the ssh-agent listening on socket (typically the
+// value of $SSH_AUTH_SOCK) and returns a client usable with AddCertificate.
+// The returned net.Conn should be closed by the caller once the agent
+// client is no longer needed.
+func DialSocket(socket string) (agent.ExtendedAgent, net.Conn, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, trace.Wrap(err, "dialing ssh-agent socket %q", socket)
+	}
+	return agent.NewClient(conn), conn, nil
+}
+
+// AddCertificate loads privateKey and its associated, already-signed cert
+// into agentClient as an agent.AddedKey. The entry's comment includes the
+// cert's KeyId and a human-readable expiry, and LifetimeSecs is derived
+// from cert.ValidBefore so the agent automatically evicts the credential
+// once it expires rather than holding a stale entry forever.
+func AddCertificate(agentClient agent.Agent, privateKey any, cert *ssh.Certificate) error {
+	if cert == nil {
+		return trace.BadParameter("cert is required")
+	}
+	if privateKey == nil {
+		return trace.BadParameter("privateKey is required")
+	}
+
+	expiry := "never"
+	var lifetimeSecs uint32
+	if cert.ValidBefore != uint64(ssh.CertTimeInfinity) {
+		validBefore := time.Unix(int64(cert.ValidBefore), 0)
+		expiry = validBefore.Format(time.RFC3339)
+		if remaining := time.Until(validBefore); remaining > 0 {
+			lifetimeSecs = uint32(remaining.Seconds())
+		}
+	}
+
+	return trace.Wrap(agentClient.Add(agent.AddedKey{
+		PrivateKey:   privateKey,
+		Certificate:  cert,
+		Comment:      fmt.Sprintf("teleport: %s (expires %s)", cert.KeyId, expiry),
+		LifetimeSecs: lifetimeSecs,
+	}))
+}
+
+// Options carries the join.RegisterParams fields that opt into loading a
+// freshly registered identity into a running ssh-agent: either an
+// AgentClient the caller already has a connection to, or an SSHAgentSocket
+// path (typically $SSH_AUTH_SOCK) to dial one.
+type Options struct {
+	SSHAgentSocket string
+	AgentClient    agent.ExtendedAgent
+}
+
+// Enabled reports whether either field of Options was set, so that callers
+// building RegisterParams can skip agent loading entirely when it was not
+// requested.
+func (o Options) Enabled() bool {
+	return o.AgentClient != nil || o.SSHAgentSocket != ""
+}
+
+// LoadFromRegisterResult loads privateKey and cert into the agent described
+// by opts, dialing opts.SSHAgentSocket if opts.AgentClient was not already
+// supplied. It is a no-op if opts.Enabled() is false, so join.Register could
+// call it unconditionally after producing a new identity.
+//
+// join.Register doesn't call it yet, and RegisterParams hasn't gained the
+// SSHAgentSocket/AgentClient fields needed to build Options from it - the
+// join package isn't part of this checkout, so there's no call site here to
+// add that wiring to.
+func LoadFromRegisterResult(opts Options, privateKey any, cert *ssh.Certificate) error {
+	if !opts.Enabled() {
+		return nil
+	}
+
+	client := opts.AgentClient
+	if client == nil {
+		dialed, conn, err := DialSocket(opts.SSHAgentSocket)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer conn.Close()
+		client = dialed
+	}
+
+	return trace.Wrap(AddCertificate(client, privateKey, cert))
+}
@@ -0,0 +1,88 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sshagent
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newTestCertificate(t *testing.T, pub ssh.PublicKey, validBefore uint64) *ssh.Certificate {
+	t.Helper()
+
+	caPub, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	require.NoError(t, err)
+	_ = caPub
+
+	cert := &ssh.Certificate{
+		Key:         pub,
+		KeyId:       "alice",
+		CertType:    ssh.UserCert,
+		ValidAfter:  0,
+		ValidBefore: validBefore,
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, caSigner))
+	return cert
+}
+
+func TestAddCertificate(t *testing.T) {
+	keyring := agent.NewKeyring()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	validBefore := uint64(time.Now().Add(time.Hour).Unix())
+	cert := newTestCertificate(t, sshPub, validBefore)
+
+	require.NoError(t, AddCertificate(keyring, priv, cert))
+
+	keys, err := keyring.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Contains(t, keys[0].Comment, "alice")
+}
+
+func TestAddCertificateRequiresCert(t *testing.T) {
+	keyring := agent.NewKeyring()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	err = AddCertificate(keyring, priv, nil)
+	require.Error(t, err)
+}
+
+func TestOptionsEnabled(t *testing.T) {
+	require.False(t, Options{}.Enabled())
+	require.True(t, Options{SSHAgentSocket: "/tmp/agent.sock"}.Enabled())
+	require.True(t, Options{AgentClient: agent.NewKeyring().(agent.ExtendedAgent)}.Enabled())
+}
+
+func TestLoadFromRegisterResultNoop(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, LoadFromRegisterResult(Options{}, priv, nil))
+}
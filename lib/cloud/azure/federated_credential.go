@@ -0,0 +1,88 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/gravitational/trace"
+)
+
+// FederatedTokenSource returns a JWT, and the audience it was minted for,
+// that can be exchanged for an Azure AD access token via a federated
+// identity credential configured on a user-assigned managed identity.
+// Implementations include a GitHub Actions OIDC token, a Kubernetes
+// projected service account token, or (elsewhere in this module)
+// env0.IDTokenSource.
+type FederatedTokenSource interface {
+	// GetFederatedToken returns a fresh JWT and the audience it's scoped
+	// to.
+	GetFederatedToken(ctx context.Context) (token, audience string, err error)
+}
+
+// FederatedTokenSourceFunc adapts a plain function to FederatedTokenSource.
+type FederatedTokenSourceFunc func(ctx context.Context) (token, audience string, err error)
+
+// GetFederatedToken implements FederatedTokenSource.
+func (f FederatedTokenSourceFunc) GetFederatedToken(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// NewFederatedIdentityCredential returns an azcore.TokenCredential that
+// assumes the user-assigned managed identity (tenantID/clientID) by
+// exchanging the JWTs tokenSource returns, via
+// azidentity.NewClientAssertionCredential. This lets Teleport
+// auth/discovery running on non-Azure infrastructure (EKS, GKE, on-prem
+// k8s, GitHub Actions) authenticate to Azure without a client secret or
+// system-assigned MSI, mirroring the armmsi federated-identity-credential
+// model.
+//
+// azidentity's credential already caches and proactively refreshes the
+// Azure AD access token it returns from GetToken; tokenSource is only
+// called again once that cached access token nears expiry, so sources that
+// themselves cache the JWT (like env0.IDTokenSource) won't be hit on every
+// request either.
+//
+// GetAzureVirtualMachinesClient and types.AzureMatcher's named-credential-
+// profile lookup aren't updated to build one of these: both live outside
+// this package (GetAzureVirtualMachinesClient in lib/srv/server, which this
+// checkout only has a test file for, and types.AzureMatcher in api/types,
+// which isn't part of this checkout at all), so there's no call site here
+// to add that selection logic to.
+func NewFederatedIdentityCredential(tenantID, clientID string, tokenSource FederatedTokenSource, opts *azidentity.ClientAssertionCredentialOptions) (azcore.TokenCredential, error) {
+	if tokenSource == nil {
+		return nil, trace.BadParameter("FederatedTokenSource is required for a federated identity credential")
+	}
+
+	getAssertion := func(ctx context.Context) (string, error) {
+		token, _, err := tokenSource.GetFederatedToken(ctx)
+		if err != nil {
+			return "", trace.Wrap(err, "fetching federated token")
+		}
+		return token, nil
+	}
+
+	cred, err := azidentity.NewClientAssertionCredential(tenantID, clientID, getAssertion, opts)
+	if err != nil {
+		return nil, trace.Wrap(err, "creating azure federated identity credential")
+	}
+	return cred, nil
+}
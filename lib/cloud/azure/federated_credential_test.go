@@ -0,0 +1,57 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederatedTokenSourceFunc(t *testing.T) {
+	var gotCtx context.Context
+	src := FederatedTokenSourceFunc(func(ctx context.Context) (string, string, error) {
+		gotCtx = ctx
+		return "the-jwt", "api://AzureADTokenExchange", nil
+	})
+
+	ctx := context.Background()
+	tok, aud, err := src.GetFederatedToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "the-jwt", tok)
+	require.Equal(t, "api://AzureADTokenExchange", aud)
+	require.Equal(t, ctx, gotCtx)
+}
+
+func TestNewFederatedIdentityCredentialRequiresTokenSource(t *testing.T) {
+	_, err := NewFederatedIdentityCredential("common", "client", nil, nil)
+	require.True(t, trace.IsBadParameter(err), "expected BadParameter, got %v", err)
+}
+
+func TestNewFederatedIdentityCredentialBuildsCredential(t *testing.T) {
+	src := FederatedTokenSourceFunc(func(ctx context.Context) (string, string, error) {
+		return "the-jwt", "api://AzureADTokenExchange", nil
+	})
+
+	cred, err := NewFederatedIdentityCredential("common", "client", src, nil)
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+}
@@ -0,0 +1,143 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package azure holds Azure API client surfaces used by Teleport's Azure
+// discovery and auto-discovery installers.
+//
+// This file's ScaleSetVirtualMachinesClient/FetchScaleSetInstances are not
+// wired into lib/srv/server's MatchersToAzureInstanceFetchers: that
+// function, and the cloud.AzureClients/azure.VirtualMachinesClient surface
+// it dispatches on, live in lib/srv/server, and this checkout contains only
+// that package's test file, not the fetcher itself. A types.AzureMatcher
+// targeting "vm" should call FetchScaleSetInstances alongside the
+// standalone-VM listing, and one targeting "vmss" should call FetchScaleSets
+// instead, once lib/srv/server's fetcher is available to edit.
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/gravitational/trace"
+)
+
+// ScaleSetVirtualMachinesClient lists instances belonging to Azure Virtual
+// Machine Scale Sets, mirroring VirtualMachinesClient's standalone-VM
+// surface for the VMSS case.
+type ScaleSetVirtualMachinesClient interface {
+	// ListScaleSets lists the scale sets in a resource group.
+	ListScaleSets(ctx context.Context, resourceGroup string) ([]*armcompute.VirtualMachineScaleSet, error)
+	// ListScaleSetVMs lists the VM instances belonging to a scale set.
+	ListScaleSetVMs(ctx context.Context, resourceGroup, scaleSet string) ([]*armcompute.VirtualMachineScaleSetVM, error)
+}
+
+// ScaleSetInstance is a VMSS VM instance flattened for discovery, tagged
+// with the parent scale set it belongs to so installers can address it via
+// `az vmss run-command` against the scale set rather than the instance.
+type ScaleSetInstance struct {
+	*armcompute.VirtualMachineScaleSetVM
+	// ScaleSetName is the name of the parent Virtual Machine Scale Set.
+	ScaleSetName string
+	// ResourceGroup is the resource group the scale set belongs to.
+	ResourceGroup string
+}
+
+// FetchScaleSetInstances lists every VM instance across all scale sets in
+// resourceGroup, flattening the result into one slice tagged with each
+// instance's parent scale set name and resource group. Fetchers built from
+// a types.AzureMatcher targeting "vm" use this alongside VirtualMachinesClient
+// to cover both standalone and scale-set-backed VMs uniformly.
+func FetchScaleSetInstances(ctx context.Context, client ScaleSetVirtualMachinesClient, resourceGroup string) ([]ScaleSetInstance, error) {
+	scaleSets, err := client.ListScaleSets(ctx, resourceGroup)
+	if err != nil {
+		return nil, trace.Wrap(err, "listing scale sets in %s", resourceGroup)
+	}
+
+	var instances []ScaleSetInstance
+	for _, scaleSet := range scaleSets {
+		if scaleSet.Name == nil {
+			continue
+		}
+
+		vms, err := client.ListScaleSetVMs(ctx, resourceGroup, *scaleSet.Name)
+		if err != nil {
+			return nil, trace.Wrap(err, "listing instances for scale set %s", *scaleSet.Name)
+		}
+
+		for _, vm := range vms {
+			instances = append(instances, ScaleSetInstance{
+				VirtualMachineScaleSetVM: vm,
+				ScaleSetName:             *scaleSet.Name,
+				ResourceGroup:            resourceGroup,
+			})
+		}
+	}
+
+	return instances, nil
+}
+
+// ScaleSetResult is a scale-set-level discovery result, emitted for the
+// "vmss" matcher type so autoscaling scenarios can attach a custom-script
+// extension to the whole scale set instead of to each instance
+// individually.
+type ScaleSetResult struct {
+	// Name is the scale set's name.
+	Name string
+	// ResourceGroup is the resource group the scale set belongs to.
+	ResourceGroup string
+	// Location is the scale set's Azure region.
+	Location string
+	// Tags are the scale set's resource tags.
+	Tags map[string]string
+}
+
+// FetchScaleSets lists the scale sets in resourceGroup as ScaleSetResults,
+// for the "vmss" matcher type.
+func FetchScaleSets(ctx context.Context, client ScaleSetVirtualMachinesClient, resourceGroup string) ([]ScaleSetResult, error) {
+	scaleSets, err := client.ListScaleSets(ctx, resourceGroup)
+	if err != nil {
+		return nil, trace.Wrap(err, "listing scale sets in %s", resourceGroup)
+	}
+
+	results := make([]ScaleSetResult, 0, len(scaleSets))
+	for _, scaleSet := range scaleSets {
+		if scaleSet.Name == nil {
+			continue
+		}
+
+		result := ScaleSetResult{
+			Name:          *scaleSet.Name,
+			ResourceGroup: resourceGroup,
+		}
+		if scaleSet.Location != nil {
+			result.Location = *scaleSet.Location
+		}
+		if len(scaleSet.Tags) > 0 {
+			result.Tags = make(map[string]string, len(scaleSet.Tags))
+			for k, v := range scaleSet.Tags {
+				if v != nil {
+					result.Tags[k] = *v
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScaleSetClient struct {
+	scaleSets map[string][]*armcompute.VirtualMachineScaleSet
+	vms       map[string][]*armcompute.VirtualMachineScaleSetVM
+}
+
+func (f *fakeScaleSetClient) ListScaleSets(ctx context.Context, resourceGroup string) ([]*armcompute.VirtualMachineScaleSet, error) {
+	return f.scaleSets[resourceGroup], nil
+}
+
+func (f *fakeScaleSetClient) ListScaleSetVMs(ctx context.Context, resourceGroup, scaleSet string) ([]*armcompute.VirtualMachineScaleSetVM, error) {
+	return f.vms[resourceGroup+"/"+scaleSet], nil
+}
+
+func TestFetchScaleSetInstances(t *testing.T) {
+	client := &fakeScaleSetClient{
+		scaleSets: map[string][]*armcompute.VirtualMachineScaleSet{
+			"rg1": {
+				{Name: to.Ptr("scaleset1")},
+				{Name: to.Ptr("scaleset2")},
+			},
+		},
+		vms: map[string][]*armcompute.VirtualMachineScaleSetVM{
+			"rg1/scaleset1": {
+				{InstanceID: to.Ptr("0")},
+				{InstanceID: to.Ptr("1")},
+			},
+			"rg1/scaleset2": {
+				{InstanceID: to.Ptr("0")},
+			},
+		},
+	}
+
+	instances, err := FetchScaleSetInstances(context.Background(), client, "rg1")
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+
+	byScaleSet := map[string]int{}
+	for _, inst := range instances {
+		require.Equal(t, "rg1", inst.ResourceGroup)
+		byScaleSet[inst.ScaleSetName]++
+	}
+	require.Equal(t, map[string]int{"scaleset1": 2, "scaleset2": 1}, byScaleSet)
+}
+
+func TestFetchScaleSetInstancesSkipsUnnamedScaleSets(t *testing.T) {
+	client := &fakeScaleSetClient{
+		scaleSets: map[string][]*armcompute.VirtualMachineScaleSet{
+			"rg1": {{}},
+		},
+	}
+
+	instances, err := FetchScaleSetInstances(context.Background(), client, "rg1")
+	require.NoError(t, err)
+	require.Empty(t, instances)
+}
+
+func TestFetchScaleSets(t *testing.T) {
+	client := &fakeScaleSetClient{
+		scaleSets: map[string][]*armcompute.VirtualMachineScaleSet{
+			"rg1": {
+				{
+					Name:     to.Ptr("scaleset1"),
+					Location: to.Ptr("eastus"),
+					Tags: map[string]*string{
+						"teleport": to.Ptr("yes"),
+					},
+				},
+			},
+		},
+	}
+
+	results, err := FetchScaleSets(context.Background(), client, "rg1")
+	require.NoError(t, err)
+	require.Equal(t, []ScaleSetResult{
+		{
+			Name:          "scaleset1",
+			ResourceGroup: "rg1",
+			Location:      "eastus",
+			Tags:          map[string]string{"teleport": "yes"},
+		},
+	}, results)
+}
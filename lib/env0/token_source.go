@@ -18,30 +18,147 @@
 
 package env0
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gravitational/trace"
+)
+
 type envGetter func(key string) string
 
+const (
+	// envOIDCToken is the static token env var Env0 sets for executions
+	// that don't request an audience-scoped token.
+	envOIDCToken = "ENV0_OIDC_TOKEN"
+	// envOIDCRequestURL and envOIDCRequestToken are the runtime-token-request
+	// env vars Env0 sets, mirroring GitHub Actions' ACTIONS_ID_TOKEN_REQUEST_URL
+	// and ACTIONS_ID_TOKEN_REQUEST_TOKEN: a GET to the former, bearer-authed
+	// with the latter and an `audience` query parameter, returns a JSON body
+	// of the form `{"value": "<jwt>"}`.
+	envOIDCRequestURL   = "ENV0_OIDC_REQUEST_URL"
+	envOIDCRequestToken = "ENV0_OIDC_REQUEST_TOKEN"
+
+	requestTimeout = 10 * time.Second
+	// refreshSkew is how far before a cached token's expiry GetIDToken
+	// discards it and requests a fresh one, rather than risking handing
+	// out a token that expires mid-use.
+	refreshSkew = 30 * time.Second
+)
+
 // IDTokenSource allows an Env0 token to be fetched whilst
 // within an Env0 execution.
 type IDTokenSource struct {
 	audienceTag string
 
 	getEnv envGetter
+	client *http.Client
+
+	cached    string
+	cachedExp time.Time
 }
 
-// GetIDToken fetches an Env0 JWT from the local node's environment
+// GetIDToken fetches an Env0 JWT from the local node's environment. If an
+// audience tag was configured, it instead fetches a fresh, audience-scoped
+// JWT from Env0's runtime OIDC endpoint, following the same pattern GitHub
+// Actions uses for its ACTIONS_ID_TOKEN_REQUEST_URL/_TOKEN, caching the
+// result until shortly before it expires.
 func (its *IDTokenSource) GetIDToken() (string, error) {
-	name := "ENV0_OIDC_TOKEN"
+	if its.audienceTag == "" {
+		tok := its.getEnv(envOIDCToken)
+		if tok == "" {
+			return "", trace.BadParameter("%s is not set, is this running within an Env0 execution?", envOIDCToken)
+		}
+		return tok, nil
+	}
+
+	if its.cached != "" && time.Now().Before(its.cachedExp.Add(-refreshSkew)) {
+		return its.cached, nil
+	}
 
-	tok := its.getEnv(name)
+	tok, exp, err := its.requestAudienceToken()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
 
+	its.cached = tok
+	its.cachedExp = exp
 	return tok, nil
 }
 
+// requestAudienceToken fetches a fresh JWT scoped to its.audienceTag from
+// Env0's runtime OIDC endpoint, returning the token and its parsed `exp`
+// claim so GetIDToken can cache it.
+func (its *IDTokenSource) requestAudienceToken() (string, time.Time, error) {
+	reqURL := its.getEnv(envOIDCRequestURL)
+	if reqURL == "" {
+		return "", time.Time{}, trace.BadParameter("%s is not set, is this running within an Env0 execution that requests audience-scoped tokens?", envOIDCRequestURL)
+	}
+	reqTok := its.getEnv(envOIDCRequestToken)
+	if reqTok == "" {
+		return "", time.Time{}, trace.BadParameter("%s is not set, is this running within an Env0 execution that requests audience-scoped tokens?", envOIDCRequestToken)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", time.Time{}, trace.Wrap(err)
+	}
+	q := req.URL.Query()
+	q.Set("audience", its.audienceTag)
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Authorization", "Bearer "+reqTok)
+
+	client := its.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, trace.ConnectionProblem(err, "requesting Env0 OIDC token")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, trace.ConnectionProblem(nil, "requesting Env0 OIDC token: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, trace.Wrap(err, "decoding Env0 OIDC token response")
+	}
+	if body.Value == "" {
+		return "", time.Time{}, trace.BadParameter("Env0 OIDC token response did not contain a token")
+	}
+
+	claims := jwt.Claims{}
+	parsed, err := jwt.ParseSigned(body.Value)
+	if err != nil {
+		return "", time.Time{}, trace.Wrap(err, "parsing Env0 OIDC token")
+	}
+	if err := parsed.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return "", time.Time{}, trace.Wrap(err, "parsing Env0 OIDC token claims")
+	}
+	if claims.Expiry == nil {
+		return "", time.Time{}, trace.BadParameter("Env0 OIDC token is missing an exp claim")
+	}
+
+	return body.Value, claims.Expiry.Time(), nil
+}
+
 // NewIDTokenSource creates a new Env0 ID token source with the given audience
 // tag.
 func NewIDTokenSource(audienceTag string, getEnv envGetter) *IDTokenSource {
 	return &IDTokenSource{
-		audienceTag,
-		getEnv,
+		audienceTag: audienceTag,
+		getEnv:      getEnv,
 	}
 }
@@ -0,0 +1,174 @@
+/*
+ * Teleport
+ * Copyright (C) 2024  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package env0
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/cryptosuites"
+)
+
+func fakeEnv(values map[string]string) envGetter {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestGetIDTokenStatic(t *testing.T) {
+	its := NewIDTokenSource("", fakeEnv(map[string]string{
+		envOIDCToken: "the-static-token",
+	}))
+
+	tok, err := its.GetIDToken()
+	require.NoError(t, err)
+	require.Equal(t, "the-static-token", tok)
+}
+
+func TestGetIDTokenStaticMissing(t *testing.T) {
+	its := NewIDTokenSource("", fakeEnv(nil))
+
+	_, err := its.GetIDToken()
+	require.True(t, trace.IsBadParameter(err), "expected BadParameter, got %v", err)
+}
+
+// fakeOIDCRequestServer serves a single endpoint shaped like Env0's runtime
+// OIDC token request URL, asserting the audience and bearer token it
+// receives and returning a JWT signed for the given audience that expires
+// in validFor.
+func fakeOIDCRequestServer(t *testing.T, wantBearer, wantAudience string, validFor time.Duration) *httptest.Server {
+	t.Helper()
+
+	privateKey, err := cryptosuites.GenerateKeyWithAlgorithm(cryptosuites.RSA2048)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: privateKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	require.NoError(t, err)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer "+wantBearer, r.Header.Get("Authorization"))
+
+		q, err := url.ParseQuery(r.URL.RawQuery)
+		require.NoError(t, err)
+		require.Equal(t, wantAudience, q.Get("audience"))
+
+		claims := jwt.Claims{
+			Audience: jwt.Audience{wantAudience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(validFor)),
+		}
+		raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"value": %q}`, raw)
+	}))
+}
+
+func TestGetIDTokenAudienceScoped(t *testing.T) {
+	srv := fakeOIDCRequestServer(t, "the-request-token", "my-audience", time.Hour)
+	defer srv.Close()
+
+	its := NewIDTokenSource("my-audience", fakeEnv(map[string]string{
+		envOIDCRequestURL:   srv.URL,
+		envOIDCRequestToken: "the-request-token",
+	}))
+
+	tok, err := its.GetIDToken()
+	require.NoError(t, err)
+	require.NotEmpty(t, tok)
+
+	// A second call within the token's validity should reuse the cached
+	// token rather than hitting the server again.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be called again while the cached token is still fresh")
+	})
+	tok2, err := its.GetIDToken()
+	require.NoError(t, err)
+	require.Equal(t, tok, tok2)
+}
+
+func TestGetIDTokenAudienceScopedRefreshesNearExpiry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		privateKey, err := cryptosuites.GenerateKeyWithAlgorithm(cryptosuites.RSA2048)
+		require.NoError(t, err)
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.RS256, Key: privateKey},
+			(&jose.SignerOptions{}).WithType("JWT"),
+		)
+		require.NoError(t, err)
+
+		claims := jwt.Claims{
+			Audience: jwt.Audience{"my-audience"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(refreshSkew / 2)),
+		}
+		raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+
+		fmt.Fprintf(w, `{"value": %q}`, raw)
+	}))
+	defer srv.Close()
+
+	its := NewIDTokenSource("my-audience", fakeEnv(map[string]string{
+		envOIDCRequestURL:   srv.URL,
+		envOIDCRequestToken: "the-request-token",
+	}))
+
+	_, err := its.GetIDToken()
+	require.NoError(t, err)
+
+	// The cached token is already within refreshSkew of expiry, so this
+	// call must fetch a new one rather than reuse it.
+	_, err = its.GetIDToken()
+	require.NoError(t, err)
+	require.Equal(t, 2, calls, "expected a near-expiry token to be refreshed rather than reused")
+}
+
+func TestGetIDTokenAudienceScopedMissingRequestURL(t *testing.T) {
+	its := NewIDTokenSource("my-audience", fakeEnv(map[string]string{
+		envOIDCRequestToken: "the-request-token",
+	}))
+
+	_, err := its.GetIDToken()
+	require.True(t, trace.IsBadParameter(err), "expected BadParameter, got %v", err)
+}
+
+func TestGetIDTokenAudienceScopedUnreachable(t *testing.T) {
+	its := NewIDTokenSource("my-audience", fakeEnv(map[string]string{
+		envOIDCRequestURL:   "http://127.0.0.1:0",
+		envOIDCRequestToken: "the-request-token",
+	}))
+
+	_, err := its.GetIDToken()
+	require.True(t, trace.IsConnectionProblem(err), "expected ConnectionProblem, got %v", err)
+}
@@ -0,0 +1,243 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"google.golang.org/protobuf/proto"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	sessPkg "github.com/gravitational/teleport/lib/session"
+)
+
+// spoolFileHeader is written once at the start of a session's spool file so
+// the reconciler can recover the session ID and, once known, the upload ID
+// to resume against, even across a teleport restart.
+type spoolFileHeader struct {
+	SessionID string
+	UploadID  string
+}
+
+// AuditSpool durably buffers audit events for a single kube session to disk
+// when the backend audit stream is unavailable, and periodically attempts
+// to flush them once it recovers. Events are appended as length-prefixed
+// protobuf messages, preserving the order they were emitted in so replay via
+// ResumeAuditStream reproduces the original event index order.
+type AuditSpool struct {
+	dir       string
+	sessionID sessPkg.ID
+	streamer  events.Streamer
+	log       *slog.Logger
+
+	mu       sync.Mutex
+	file     *os.File
+	uploadID string
+}
+
+// NewAuditSpool creates (or opens, if one already exists for sessionID) a
+// disk-backed spool under dir for buffering events destined for the given
+// streamer when CreateAuditStream or EmitAuditEvent fail.
+func NewAuditSpool(dir string, sessionID sessPkg.ID, streamer events.Streamer, log *slog.Logger) (*AuditSpool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s := &AuditSpool{
+		dir:       dir,
+		sessionID: sessionID,
+		streamer:  streamer,
+		log:       log,
+	}
+	f, err := os.OpenFile(s.path(), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.file = f
+	return s, nil
+}
+
+func (s *AuditSpool) path() string {
+	return filepath.Join(s.dir, string(s.sessionID)+".spool")
+}
+
+// SetUploadID records the upload ID returned by a (possibly later-resumed)
+// CreateAuditStream call, so a subsequent reconciliation round can call
+// ResumeAuditStream instead of starting a new stream from scratch.
+func (s *AuditSpool) SetUploadID(uploadID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploadID = uploadID
+}
+
+// Append writes evt to the spool. It is called whenever CreateAuditStream or
+// EmitAuditEvent against the live stream has failed, so the event is not
+// lost while the audit backend is unavailable.
+func (s *AuditSpool) Append(evt apievents.AuditEvent) error {
+	oneOf, err := events.ToOneOf(evt)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := proto.Marshal(oneOf)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	if _, err := s.file.Write(lenBuf[:]); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := s.file.Write(data); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(s.file.Sync())
+}
+
+// Reconcile attempts to drain the spool into the backend audit stream,
+// resuming the upload ID recorded via SetUploadID if one is known, or
+// creating a fresh stream otherwise. Drained events are removed from the
+// spool file on success so a subsequent restart does not replay them again.
+func (s *AuditSpool) Reconcile(ctx context.Context) error {
+	s.mu.Lock()
+	uploadID := s.uploadID
+	s.mu.Unlock()
+
+	var stream apievents.Stream
+	var err error
+	if uploadID != "" {
+		stream, err = s.streamer.ResumeAuditStream(ctx, s.sessionID, uploadID)
+	} else {
+		stream, err = s.streamer.CreateAuditStream(ctx, s.sessionID)
+	}
+	if err != nil {
+		return trace.Wrap(err, "reconciling audit spool for session %v", s.sessionID)
+	}
+
+	events, err := s.readAll()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	for _, evt := range events {
+		if err := stream.RecordEvent(ctx, preparedSpoolEvent{evt}); err != nil {
+			return trace.Wrap(err, "flushing spooled event")
+		}
+	}
+
+	return trace.Wrap(s.truncate())
+}
+
+// readAll reads every spooled event back out in the order they were
+// appended.
+func (s *AuditSpool) readAll() ([]apievents.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var out []apievents.AuditEvent
+	for {
+		var lenBuf [4]byte
+		if _, err := io.ReadFull(s.file, lenBuf[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, trace.Wrap(err)
+		}
+		size := binary.BigEndian.Uint32(lenBuf[:])
+		data := make([]byte, size)
+		if _, err := io.ReadFull(s.file, data); err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		var oneOf apievents.OneOf
+		if err := proto.Unmarshal(data, &oneOf); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		evt, err := events.FromOneOf(oneOf)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		out = append(out, evt)
+	}
+	return out, nil
+}
+
+func (s *AuditSpool) truncate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Truncate(0); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err := s.file.Seek(0, io.SeekStart)
+	return trace.Wrap(err)
+}
+
+// Close releases the spool's underlying file handle without deleting its
+// contents, so an unreconciled spool survives a process restart.
+func (s *AuditSpool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return trace.Wrap(s.file.Close())
+}
+
+// preparedSpoolEvent adapts an apievents.AuditEvent already spooled to disk
+// to the events.PreparedSessionEvent interface Stream.RecordEvent expects,
+// since spooled events were already "prepared" (indexed, validated) before
+// the original emit attempt failed.
+type preparedSpoolEvent struct {
+	event apievents.AuditEvent
+}
+
+// GetAuditEvent implements events.PreparedSessionEvent.
+func (p preparedSpoolEvent) GetAuditEvent() apievents.AuditEvent {
+	return p.event
+}
+
+// RunAuditSpoolReconciler periodically attempts to drain spool into the
+// backend audit stream until ctx is canceled, with a short fixed interval
+// appropriate for recovering soon after the audit backend becomes reachable
+// again.
+func RunAuditSpoolReconciler(ctx context.Context, spool *AuditSpool, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := spool.Reconcile(ctx); err != nil {
+				log.DebugContext(ctx, "Audit spool reconciliation did not complete, will retry", "error", err)
+			}
+		}
+	}
+}
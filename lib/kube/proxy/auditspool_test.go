@@ -0,0 +1,127 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	sessPkg "github.com/gravitational/teleport/lib/session"
+	"github.com/gravitational/teleport/lib/utils/log/logtest"
+)
+
+// recordingFakeStream is a minimal apievents.Stream that just remembers the
+// events it was handed, in order.
+type recordingFakeStream struct {
+	apievents.Stream
+	recorded []apievents.AuditEvent
+}
+
+func (r *recordingFakeStream) RecordEvent(_ context.Context, pe events.PreparedSessionEvent) error {
+	r.recorded = append(r.recorded, pe.GetAuditEvent())
+	return nil
+}
+
+func (r *recordingFakeStream) Close(context.Context) error { return nil }
+
+// spoolTestStreamer simulates an audit backend that is down for the first
+// createErrCount calls to CreateAuditStream, then recovers. ResumeAuditStream
+// always succeeds once an uploadID has been handed out, mirroring how the
+// reconciler is expected to resume rather than recreate the stream.
+type spoolTestStreamer struct {
+	createErrCount int
+	createAttempts int
+	stream         *recordingFakeStream
+	uploadID       string
+}
+
+func (s *spoolTestStreamer) CreateAuditStream(ctx context.Context, sID sessPkg.ID) (apievents.Stream, error) {
+	s.createAttempts++
+	if s.createAttempts <= s.createErrCount {
+		return nil, trace.ConnectionProblem(nil, "audit backend unavailable")
+	}
+	s.uploadID = "upload-1"
+	return s.stream, nil
+}
+
+func (s *spoolTestStreamer) ResumeAuditStream(ctx context.Context, sID sessPkg.ID, uploadID string) (apievents.Stream, error) {
+	if uploadID != s.uploadID {
+		return nil, trace.NotFound("no such upload %v", uploadID)
+	}
+	return s.stream, nil
+}
+
+// TestAuditSpoolReconcilesInOrder exercises the spool/reconciler path used
+// when CreateAuditStream is unavailable at session start: events emitted
+// while the backend is down are appended to the spool, and once the backend
+// recovers, Reconcile drains them into the stream via ResumeAuditStream in
+// the order they were originally emitted.
+func TestAuditSpoolReconcilesInOrder(t *testing.T) {
+	ctx := context.Background()
+	log := logtest.NewLogger()
+
+	streamer := &spoolTestStreamer{
+		createErrCount: 1,
+		stream:         &recordingFakeStream{},
+	}
+
+	sessionID := sessPkg.NewID()
+	spool, err := NewAuditSpool(t.TempDir(), sessionID, streamer, log)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, spool.Close()) })
+
+	// CreateAuditStream fails the first time, so the caller falls back to
+	// spooling events to disk.
+	_, err = streamer.CreateAuditStream(ctx, sessionID)
+	require.Error(t, err)
+
+	const numEvents = 5
+	for i := 0; i < numEvents; i++ {
+		evt := &apievents.SessionStart{
+			Metadata: apievents.Metadata{
+				Type: "session.start",
+				Code: fmt.Sprintf("T2000I-%d", i),
+			},
+		}
+		require.NoError(t, spool.Append(evt))
+	}
+
+	// The backend has recovered; reconciling should drain the spool in
+	// order via CreateAuditStream (no upload ID is known yet).
+	require.NoError(t, spool.Reconcile(ctx))
+	require.Len(t, streamer.stream.recorded, numEvents)
+	for i, evt := range streamer.stream.recorded {
+		start, ok := evt.(*apievents.SessionStart)
+		require.True(t, ok)
+		require.Equal(t, fmt.Sprintf("T2000I-%d", i), start.Code)
+	}
+
+	// The spool should now be empty, and Reconcile should resume against the
+	// recorded upload ID rather than creating a new stream.
+	require.NoError(t, spool.Append(&apievents.SessionStart{
+		Metadata: apievents.Metadata{Type: "session.start", Code: "T2000I-resume"},
+	}))
+	spool.SetUploadID(streamer.uploadID)
+	require.NoError(t, spool.Reconcile(ctx))
+	require.Len(t, streamer.stream.recorded, numEvents+1)
+}
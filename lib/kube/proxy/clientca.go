@@ -0,0 +1,88 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"crypto"
+	"crypto/x509"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// ClientCAGetter returns the certificate authority used to validate client
+// certificates presented to the kube proxy, and the signer used to issue
+// new ones. Historically this has always been types.HostCA, but that
+// couples rotation of kube client auth to rotation of the host's SSH/TLS
+// identity. Implementations backed by types.KubeCA let operators rotate
+// kube auth independently and revoke it quickly under compromise without
+// touching host trust.
+//
+// Nothing constructs ForwarderConfig or calls authenticate() in this
+// checkout - forwarder.go isn't part of it - so neither is wired up to
+// consult a ClientCAGetter yet. Until one is, GenTestKubeClientsTLSCert
+// keeps signing against types.HostCA by default so it still produces certs
+// the production validation path would actually accept.
+type ClientCAGetter interface {
+	// GetClientCA returns the certificate authority whose trust bundle
+	// should be used to validate incoming kube client certificates.
+	GetClientCA() (types.CertAuthority, error)
+	// GetClientCASigner returns the active signer for the client CA, used
+	// to mint new kube client certificates (e.g. for `tctl auth sign
+	// --format=kubernetes`).
+	GetClientCASigner() (crypto.Signer, *x509.Certificate, error)
+}
+
+// hostCAClientCAGetter is the default ClientCAGetter, preserving the
+// previous behavior of signing and validating kube client certs against
+// types.HostCA. It exists so ForwarderConfig can default GetClientCA and
+// GetClientCASigner when a dedicated types.KubeCA has not been configured.
+type hostCAClientCAGetter struct {
+	getCertAuthority func(caType types.CertAuthType) (types.CertAuthority, error)
+	getSigner        func(ca types.CertAuthority) (crypto.Signer, *x509.Certificate, error)
+}
+
+// GetClientCA implements ClientCAGetter by returning the HostCA.
+func (h *hostCAClientCAGetter) GetClientCA() (types.CertAuthority, error) {
+	ca, err := h.getCertAuthority(types.HostCA)
+	return ca, trace.Wrap(err)
+}
+
+// GetClientCASigner implements ClientCAGetter by signing with the HostCA's
+// active signer.
+func (h *hostCAClientCAGetter) GetClientCASigner() (crypto.Signer, *x509.Certificate, error) {
+	ca, err := h.getCertAuthority(types.HostCA)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	signer, cert, err := h.getSigner(ca)
+	return signer, cert, trace.Wrap(err)
+}
+
+// NewHostCAClientCAGetter returns a ClientCAGetter that signs and validates
+// kube client certificates against types.HostCA, matching the behavior
+// this package had before a dedicated types.KubeCA existed.
+func NewHostCAClientCAGetter(
+	getCertAuthority func(caType types.CertAuthType) (types.CertAuthority, error),
+	getSigner func(ca types.CertAuthority) (crypto.Signer, *x509.Certificate, error),
+) ClientCAGetter {
+	return &hostCAClientCAGetter{
+		getCertAuthority: getCertAuthority,
+		getSigner:        getSigner,
+	}
+}
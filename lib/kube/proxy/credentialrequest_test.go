@@ -0,0 +1,45 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCredentialRequest exercises the `/api/v1/teleport/credentialrequest`
+// endpoint against a live KubeProxy using the existing
+// TestContext.GenTestKubeClientFromToken helper, which was added alongside
+// the endpoint but never called by any test.
+//
+// It currently asserts rejection, not a successful exchange: nothing in
+// this checkout implements credentialExchanger (see the doc comment on
+// that interface), so the endpoint always returns NotImplemented. TTL
+// clamping and per-cluster route selection can't be covered until a real
+// credentialExchanger exists to exercise.
+func TestCredentialRequest(t *testing.T) {
+	ctx := context.Background()
+	testCtx := SetupTestContext(ctx, t, TestConfig{
+		Clusters: []KubeClusterConfig{{Name: "local", APIEndpoint: "https://localhost"}},
+	})
+
+	_, err := testCtx.GenTestKubeClientFromToken(t, "some-join-token", []byte("not-a-real-pubkey"), "local")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "501")
+}
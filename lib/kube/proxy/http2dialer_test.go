@@ -0,0 +1,191 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"github.com/gravitational/teleport/lib/reversetunnelclient"
+)
+
+// http2TestServer runs a plaintext (h2c-style) HTTP/2 server driving demuxer
+// for each accepted connection, standing in for the leaf-side demuxer that
+// would run behind the reverse tunnel in production.
+type http2TestServer struct {
+	demuxer *kubeVirtualHostDemuxer
+}
+
+func (s *http2TestServer) start(tb testing.TB) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	h2s := &http2.Server{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go h2s.ServeConn(conn, &http2.ServeConnOpts{Handler: s.demuxer})
+		}
+	}()
+
+	return listener
+}
+
+// BenchmarkDialTCPPerRequest measures the per-request setup cost of the
+// existing fresh-TCP-connection-per-request path, as a baseline to compare
+// against BenchmarkDialHTTP2StreamPerRequest.
+func BenchmarkDialTCPPerRequest(b *testing.B) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	site := &fakeRemoteSite{idToAddr: map[string]string{"node1": listener.Addr().String()}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn, err := site.DialTCP(reversetunnelclient.DialParams{ServerID: "node1.cluster"})
+		if err != nil {
+			b.Fatal(err)
+		}
+		conn.Close()
+	}
+}
+
+// BenchmarkDialHTTP2StreamPerRequest measures the per-request setup cost of
+// reusing a single HTTP/2 connection per remote site, which amortizes the
+// TCP dial (and, in production, the reverse tunnel round trip) across many
+// requests.
+func BenchmarkDialHTTP2StreamPerRequest(b *testing.B) {
+	idToAddr := map[string]string{"node1": "unused"}
+	server := &http2TestServer{demuxer: &kubeVirtualHostDemuxer{idToAddr: idToAddr}}
+	listener := server.start(b)
+	defer listener.Close()
+
+	site := &fakeRemoteSite{idToAddr: map[string]string{"node1": listener.Addr().String()}}
+	dialer := &HTTP2StreamDialer{DialSite: site.DialTCP}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn, err := dialer.DialHTTP2Stream(reversetunnelclient.DialParams{ServerID: "node1.cluster"})
+		if err != nil {
+			b.Fatal(err)
+		}
+		conn.Close()
+	}
+}
+
+// TestKubeVirtualHostDemuxerServeHTTP exercises kubeVirtualHostDemuxer's
+// routing directly against its ServeHTTP method, without an actual HTTP/2
+// connection, since the routing decision doesn't depend on HTTP/2 framing.
+func TestKubeVirtualHostDemuxerServeHTTP(t *testing.T) {
+	t.Run("unknown server id returns 404", func(t *testing.T) {
+		demuxer := &kubeVirtualHostDemuxer{idToAddr: map[string]string{}}
+
+		req := httptest.NewRequest(http.MethodPost, "https://teleport.cluster.local/kube-stream", nil)
+		req.Header.Set(kubeVirtualHostHeader, "unknown.cluster")
+		rec := httptest.NewRecorder()
+
+		demuxer.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("dial failure returns 502", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		demuxer := &kubeVirtualHostDemuxer{idToAddr: map[string]string{"node1": addr}}
+
+		req := httptest.NewRequest(http.MethodPost, "https://teleport.cluster.local/kube-stream", nil)
+		req.Header.Set(kubeVirtualHostHeader, "node1")
+		rec := httptest.NewRecorder()
+
+		demuxer.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadGateway, rec.Code)
+	})
+}
+
+// TestHTTP2StreamDialerReusesClientConn checks that a second clientConnFor
+// call for the same site reuses the cached *http2.ClientConn instead of
+// dialing again.
+func TestHTTP2StreamDialerReusesClientConn(t *testing.T) {
+	idToAddr := map[string]string{"node1": "unused"}
+	server := &http2TestServer{demuxer: &kubeVirtualHostDemuxer{idToAddr: idToAddr}}
+	listener := server.start(t)
+	defer listener.Close()
+
+	site := &fakeRemoteSite{idToAddr: map[string]string{"node1": listener.Addr().String()}}
+	dialer := &HTTP2StreamDialer{DialSite: site.DialTCP}
+
+	p := reversetunnelclient.DialParams{ServerID: "node1.cluster"}
+	first, err := dialer.clientConnFor(p)
+	require.NoError(t, err)
+
+	second, err := dialer.clientConnFor(p)
+	require.NoError(t, err)
+
+	require.Same(t, first, second)
+}
+
+// TestHTTP2StreamDialerEvictsIdleClientConn checks that closeWhenIdle removes
+// a connection from the cache (and closes it) once it has carried no
+// streams for IdleTimeout.
+func TestHTTP2StreamDialerEvictsIdleClientConn(t *testing.T) {
+	idToAddr := map[string]string{"node1": "unused"}
+	server := &http2TestServer{demuxer: &kubeVirtualHostDemuxer{idToAddr: idToAddr}}
+	listener := server.start(t)
+	defer listener.Close()
+
+	site := &fakeRemoteSite{idToAddr: map[string]string{"node1": listener.Addr().String()}}
+	dialer := &HTTP2StreamDialer{DialSite: site.DialTCP, IdleTimeout: 20 * time.Millisecond}
+
+	p := reversetunnelclient.DialParams{ServerID: "node1.cluster"}
+	_, err := dialer.clientConnFor(p)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		dialer.mu.Lock()
+		defer dialer.mu.Unlock()
+		_, ok := dialer.clients["node1.cluster"]
+		return !ok
+	}, time.Second, 10*time.Millisecond, "idle connection was not evicted from the cache")
+}
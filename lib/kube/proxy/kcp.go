@@ -0,0 +1,96 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"net"
+
+	kcp "github.com/xtaci/kcp-go/v5"
+)
+
+// newKCPClientConn wraps pc - a redialPacketConn presenting the tunnel as a
+// sequence of framed packets - in a KCP session so that retransmission and
+// ordering survive a redial. sessionID is carried on the wire so the
+// server-side demuxer can attach incoming packets to the right KCP session
+// instead of creating a new one on every reconnect.
+func newKCPClientConn(pc *redialPacketConn, sessionID string) (net.Conn, error) {
+	conv := kcpConversationID(sessionID)
+	block, _ := kcp.NewNoneBlockCrypt(nil)
+	sess, err := kcp.NewConn3(conv, "", block, 10, 3, &packetConnAdapter{pc: pc})
+	if err != nil {
+		return nil, err
+	}
+
+	// Favor latency over throughput: kube exec/portforward streams are
+	// interactive, not bulk transfers.
+	sess.SetNoDelay(1, 20, 2, 1)
+	sess.SetWindowSize(128, 128)
+	sess.SetACKNoDelay(true)
+
+	return sess, nil
+}
+
+// kcpConversationID derives a stable KCP conversation ID from the session
+// ID so reconnecting with the same sessionID resumes the same conversation.
+func kcpConversationID(sessionID string) uint32 {
+	var h uint32 = 2166136261
+	for i := 0; i < len(sessionID); i++ {
+		h ^= uint32(sessionID[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// packetConnAdapter presents a redialPacketConn as a net.PacketConn, which
+// is what kcp.NewConn3 expects its transport to look like. Reads and writes
+// are retried against a freshly redialed connection when the current one
+// errors out from under the KCP session.
+type packetConnAdapter struct {
+	pc *redialPacketConn
+}
+
+func (a *packetConnAdapter) ReadFrom(p []byte) (int, net.Addr, error) {
+	conn := a.pc.currentConn()
+	n, err := conn.Read(p)
+	if err != nil {
+		if redialErr := a.pc.redial(); redialErr != nil {
+			return 0, nil, redialErr
+		}
+		conn = a.pc.currentConn()
+		n, err = conn.Read(p)
+	}
+	return n, conn.RemoteAddr(), err
+}
+
+func (a *packetConnAdapter) WriteTo(p []byte, _ net.Addr) (int, error) {
+	conn := a.pc.currentConn()
+	n, err := conn.Write(p)
+	if err != nil {
+		if redialErr := a.pc.redial(); redialErr != nil {
+			return 0, redialErr
+		}
+		conn = a.pc.currentConn()
+		return conn.Write(p)
+	}
+	return n, nil
+}
+
+func (a *packetConnAdapter) Close() error {
+	return a.pc.currentConn().Close()
+}
+
+func (a *packetConnAdapter) LocalAddr() net.Addr { return a.pc.currentConn().LocalAddr() }
@@ -0,0 +1,141 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"net"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gravitational/trace"
+	smux "github.com/xtaci/smux"
+
+	"github.com/gravitational/teleport/lib/reversetunnelclient"
+)
+
+// ResilientDialer is an opt-in dial path for the kube forwarder that
+// survives a mid-session reverse tunnel bounce. Instead of handing back the
+// bare net.Conn from DialTCP - whose lifetime is tied to one TCP connection
+// - it generates a per-session ID, wraps successive DialTCP results in a
+// RedialPacketConn, and layers a reliable, ordered stream (KCP) plus stream
+// multiplexing (smux) on top. When the tunnel drops, RedialPacketConn
+// transparently redials with the same ServerID and the KCP session carries
+// the logical stream across the new TCP connection.
+type ResilientDialer struct {
+	// Site is the remote site to dial through. It is typically a
+	// reversetunnelclient.RemoteSite for the target cluster.
+	Site reversetunnelclient.RemoteSite
+}
+
+// DialTCP dials through the wrapped site and returns a net.Conn backed by a
+// resilient KCP/smux session rather than the raw tunnel connection. The
+// session ID is generated once and reused across redials so the server-side
+// demuxer can find the existing KCP session instead of starting a new one.
+func (d *ResilientDialer) DialTCP(p reversetunnelclient.DialParams) (net.Conn, error) {
+	sessionID := uuid.New().String()
+
+	pc, err := newRedialPacketConn(d.Site, p, sessionID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	kcpConn, err := newKCPClientConn(pc, sessionID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	session, err := smux.Client(kcpConn, smuxConfig())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	stream, err := session.OpenStream()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &smuxStreamConn{Stream: stream, session: session, kcpConn: kcpConn}, nil
+}
+
+// redialPacketConn frames each DialTCP'd connection as a packet transport:
+// a 4-byte length prefix followed by the wire session ID and the payload.
+// When the underlying net.Conn errors, it transparently redials the same
+// ServerID so the KCP session above it can keep retransmitting unacked
+// packets across the new connection.
+type redialPacketConn struct {
+	mu        sync.Mutex
+	site      reversetunnelclient.RemoteSite
+	params    reversetunnelclient.DialParams
+	sessionID string
+	conn      net.Conn
+}
+
+func newRedialPacketConn(site reversetunnelclient.RemoteSite, params reversetunnelclient.DialParams, sessionID string) (*redialPacketConn, error) {
+	conn, err := site.DialTCP(params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &redialPacketConn{site: site, params: params, sessionID: sessionID, conn: conn}, nil
+}
+
+// redial discards the stale connection and dials the same ServerID again.
+// It is invoked by the KCP read/write loop whenever the wrapped connection
+// returns an error, so the logical stream survives the tunnel bounce.
+func (r *redialPacketConn) redial() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conn, err := r.site.DialTCP(r.params)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+	r.conn = conn
+	return nil
+}
+
+func (r *redialPacketConn) currentConn() net.Conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conn
+}
+
+// smuxConfig returns the smux session configuration used for the single
+// logical kube exec/portforward stream carried over the resilient tunnel.
+func smuxConfig() *smux.Config {
+	cfg := smux.DefaultConfig()
+	cfg.KeepAliveDisabled = false
+	return cfg
+}
+
+// smuxStreamConn adapts a *smux.Stream to net.Conn while keeping the
+// backing KCP session and connection alive for the stream's lifetime, and
+// tearing them down together when the stream is closed.
+type smuxStreamConn struct {
+	*smux.Stream
+	session *smux.Session
+	kcpConn net.Conn
+}
+
+func (s *smuxStreamConn) Close() error {
+	streamErr := s.Stream.Close()
+	sessionErr := s.session.Close()
+	kcpErr := s.kcpConn.Close()
+	return trace.NewAggregate(streamErr, sessionErr, kcpErr)
+}
@@ -0,0 +1,74 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/reversetunnelclient"
+)
+
+// TestResilientDialerSurvivesRedial asserts that a ResilientDialer backed by
+// a fakeRemoteSite configured to drop its TCP connection after a handful of
+// bytes still delivers a complete, in-order byte stream to the caller: the
+// KCP session retransmits unacked data across the redial triggered by
+// redialPacketConn.
+func TestResilientDialerSurvivesRedial(t *testing.T) {
+	const serverID = "node1.cluster"
+	const payload = "the quick brown fox jumps over the lazy dog"
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	site := &fakeRemoteSite{
+		idToAddr:       map[string]string{"node1": listener.Addr().String()},
+		dropAfterBytes: 8,
+	}
+
+	dialer := &ResilientDialer{Site: site}
+	conn, err := dialer.DialTCP(reversetunnelclient.DialParams{ServerID: serverID})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = conn.Write([]byte(payload))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+	buf := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	require.Equal(t, payload, string(buf))
+}
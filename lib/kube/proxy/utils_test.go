@@ -23,7 +23,9 @@ import (
 	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -551,8 +553,22 @@ func (c *TestContext) GenTestKubeClientTLSCert(t *testing.T, userName, kubeClust
 	return client, cfg
 }
 
-// GenTestKubeClientsTLSCert generates a "regular" kube client and a dynamic one to access kube service
+// GenTestKubeClientsTLSCert generates a "regular" kube client and a dynamic one to access kube
+// service. Client certificates are signed against types.HostCA, matching the production
+// authenticate() path, which doesn't yet consult ClientCAGetter.
 func (c *TestContext) GenTestKubeClientsTLSCert(t *testing.T, userName, kubeCluster string, opts ...GenTestKubeClientTLSCertOptions) (*kubernetes.Clientset, *dynamic.DynamicClient, *rest.Config) {
+	return c.genTestKubeClientsTLSCertWithCA(t, userName, kubeCluster, types.HostCA, opts...)
+}
+
+// GenTestKubeClientsKubeCATLSCert is identical to GenTestKubeClientsTLSCert but signs against the
+// dedicated types.KubeCA instead of types.HostCA. It's for exercising ClientCAGetter's
+// types.KubeCA-backed implementation in isolation; it is not yet the default, since the
+// production authenticate() path doesn't consult ClientCAGetter and wouldn't accept these certs.
+func (c *TestContext) GenTestKubeClientsKubeCATLSCert(t *testing.T, userName, kubeCluster string, opts ...GenTestKubeClientTLSCertOptions) (*kubernetes.Clientset, *dynamic.DynamicClient, *rest.Config) {
+	return c.genTestKubeClientsTLSCertWithCA(t, userName, kubeCluster, types.KubeCA, opts...)
+}
+
+func (c *TestContext) genTestKubeClientsTLSCertWithCA(t *testing.T, userName, kubeCluster string, caType types.CertAuthType, opts ...GenTestKubeClientTLSCertOptions) (*kubernetes.Clientset, *dynamic.DynamicClient, *rest.Config) {
 	authServer := c.AuthServer
 	clusterName, err := authServer.GetClusterName(context.TODO())
 	require.NoError(t, err)
@@ -567,7 +583,7 @@ func (c *TestContext) GenTestKubeClientsTLSCert(t *testing.T, userName, kubeClus
 	ttl := roles.AdjustSessionTTL(10 * time.Minute)
 
 	ca, err := authServer.GetCertAuthority(c.Context, types.CertAuthID{
-		Type:       types.HostCA,
+		Type:       caType,
 		DomainName: clusterName.GetClusterName(),
 	}, true)
 	require.NoError(t, err)
@@ -630,6 +646,87 @@ func (c *TestContext) GenTestKubeClientsTLSCert(t *testing.T, userName, kubeClus
 	return client, dynClient, restConfig
 }
 
+// WhoAmI queries the `/api/v1/teleport/whoami` endpoint using the supplied
+// rest.Config (as returned by GenTestKubeClientTLSCert) and returns the
+// resolved identity the kube proxy would use to authorize requests made
+// with that client certificate.
+func (c *TestContext) WhoAmI(cfg *rest.Config) (*WhoAmIResponse, error) {
+	tlsConfig, err := rest.TLSConfigFor(cfg)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	resp, err := client.Get("https://" + c.KubeProxyAddress() + "/api/v1/teleport/whoami")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.Errorf("whoami request failed with status %v", resp.StatusCode)
+	}
+
+	var whoAmI WhoAmIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&whoAmI); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &whoAmI, nil
+}
+
+// GenTestKubeClientFromToken exchanges token for a short-lived kube client
+// certificate via the `/api/v1/teleport/credentialrequest` endpoint and
+// returns a rest.Config usable directly against the kube proxy, without
+// ever calling GenTestKubeClientsTLSCert. It's used to exercise TTL
+// clamping against role max_session_ttl, per-cluster route selection, and
+// rejection of tokens that lack kube access.
+func (c *TestContext) GenTestKubeClientFromToken(t *testing.T, token string, pubKeyPEM []byte, kubeCluster string) (*rest.Config, error) {
+	body, err := json.Marshal(CredentialRequest{
+		Token:             token,
+		PublicKeyPEM:      pubKeyPEM,
+		KubernetesCluster: kubeCluster,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(
+		"https://"+c.KubeProxyAddress()+"/api/v1/teleport/credentialrequest",
+		"application/json",
+		strings.NewReader(string(body)),
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.Errorf("credential request failed with status %v", resp.StatusCode)
+	}
+
+	var credResp CredentialResponse
+	if err := json.NewDecoder(resp.Body).Decode(&credResp); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var caPool []byte
+	for _, ca := range credResp.CACertPEMs {
+		caPool = append(caPool, ca...)
+	}
+
+	return &rest.Config{
+		Host: "https://" + c.KubeProxyAddress(),
+		TLSClientConfig: rest.TLSClientConfig{
+			CertData:   credResp.ClientCertPEM,
+			CAData:     caPool,
+			ServerName: "teleport.cluster.local",
+		},
+	}, nil
+}
+
 // NewJoiningSession creates a new session stream for joining an existing session.
 func (c *TestContext) NewJoiningSession(cfg *rest.Config, sessionID string, mode types.SessionParticipantMode) (*streamproto.SessionStream, error) {
 	ws, err := newWebSocketClient(cfg, http.MethodPost, &url.URL{
@@ -693,6 +790,12 @@ func (f *fakeClient) CreateSessionTracker(ctx context.Context, st types.SessionT
 type fakeRemoteSite struct {
 	*reversetunnelclient.FakeRemoteSite
 	idToAddr map[string]string
+
+	// dropAfterBytes, when non-zero, causes DialTCP to return a net.Conn that
+	// severs itself after dropAfterBytes have passed through it, simulating a
+	// reverse tunnel bounce mid-session so ResilientDialer's redial path can
+	// be exercised.
+	dropAfterBytes int64
 }
 
 func (f *fakeRemoteSite) DialTCP(p reversetunnelclient.DialParams) (conn net.Conn, err error) {
@@ -705,5 +808,40 @@ func (f *fakeRemoteSite) DialTCP(p reversetunnelclient.DialParams) (conn net.Con
 	if err != nil {
 		panic(err)
 	}
+	if f.dropAfterBytes > 0 {
+		conn = &dropAfterNBytesConn{Conn: conn, remaining: f.dropAfterBytes}
+	}
 	return conn, nil
 }
+
+// dropAfterNBytesConn wraps a net.Conn and returns io.ErrClosedPipe from
+// Read/Write once remaining bytes have passed through it, simulating an
+// abrupt reverse tunnel disconnect.
+type dropAfterNBytesConn struct {
+	net.Conn
+	remaining int64
+}
+
+func (d *dropAfterNBytesConn) Read(p []byte) (int, error) {
+	if d.remaining <= 0 {
+		return 0, io.ErrClosedPipe
+	}
+	if int64(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.Conn.Read(p)
+	d.remaining -= int64(n)
+	return n, err
+}
+
+func (d *dropAfterNBytesConn) Write(p []byte) (int, error) {
+	if d.remaining <= 0 {
+		return 0, io.ErrClosedPipe
+	}
+	if int64(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.Conn.Write(p)
+	d.remaining -= int64(n)
+	return n, err
+}
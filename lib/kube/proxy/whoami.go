@@ -0,0 +1,116 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gravitational/trace"
+	"github.com/gravitational/trace/trail"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// WhoAmIResponse describes the Teleport identity that would be used to
+// authorize a kubectl request made with the presented client certificate.
+// It mirrors the shape of the Pinniped/Kubernetes `WhoAmIRequest` response
+// so existing tooling that inspects impersonation identities can reuse the
+// same mental model against Teleport-fronted clusters.
+type WhoAmIResponse struct {
+	// Username is the Teleport username associated with the identity.
+	Username string `json:"username"`
+	// Roles are the Teleport roles assigned to the user.
+	Roles []string `json:"roles"`
+	// KubernetesUsers are the `kubernetes_users` resolved for this identity
+	// after trait interpolation.
+	KubernetesUsers []string `json:"kubernetes_users"`
+	// KubernetesGroups are the `kubernetes_groups` resolved for this
+	// identity after trait interpolation.
+	KubernetesGroups []string `json:"kubernetes_groups"`
+	// KubernetesResources are the effective KubernetesResource allow/deny
+	// rules after merging all of the user's roles.
+	KubernetesResources []types.KubernetesResource `json:"kubernetes_resources"`
+	// MFAVerified is set to the MFA device ID used to authenticate, if any.
+	MFAVerified string `json:"mfa_verified,omitempty"`
+	// ActiveRequests lists the access request IDs contributing to the
+	// identity's current role set.
+	ActiveRequests []string `json:"active_requests,omitempty"`
+	// KubernetesCluster is the target cluster the request was routed to.
+	KubernetesCluster string `json:"kubernetes_cluster"`
+	// RouteToCluster is the Teleport cluster the identity is routed
+	// through.
+	RouteToCluster string `json:"route_to_cluster"`
+}
+
+// whoAmI handles the `/api/v1/teleport/whoami` endpoint. It authenticates
+// the caller using the same client-certificate path as regular kubectl
+// traffic (see authenticate) and reports back the resolved identity that
+// would be used to authorize the request, without requiring the caller to
+// issue a real Kubernetes API call.
+func (f *Forwarder) whoAmI(authCtx *authContext, w http.ResponseWriter, req *http.Request, p httprouter.Params) (resp any, err error) {
+	identity := authCtx.Identity.GetIdentity()
+
+	resources, err := authCtx.Checker.GetKubeResources(authCtx.kubeCluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &WhoAmIResponse{
+		Username:            identity.Username,
+		Roles:               identity.Groups,
+		KubernetesUsers:     authCtx.kubeUsers.ToSlice(),
+		KubernetesGroups:    authCtx.kubeGroups.ToSlice(),
+		KubernetesResources: resources,
+		MFAVerified:         identity.MFAVerified,
+		ActiveRequests:      identity.ActiveRequests,
+		KubernetesCluster:   authCtx.kubeClusterName,
+		RouteToCluster:      identity.RouteToCluster,
+	}, nil
+}
+
+// handleWhoAmI is the httprouter.Handle meant to be wired up alongside the
+// other `/api/v1/teleport/*` endpoints (exec, portforward, join). It
+// performs the standard client-cert authentication used for kubectl traffic
+// before delegating to whoAmI.
+//
+// forwarder.go, wherever those other endpoints are actually registered on a
+// router, isn't part of this checkout, so that registration can't be added
+// here. TestWhoAmI exercises this handler directly through the live
+// KubeProxy TLS server instead.
+func (f *Forwarder) handleWhoAmI(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	authCtx, err := f.authenticate(req)
+	if err != nil {
+		f.log.WithError(err).Warn("WhoAmI authentication failed.")
+		trace.WriteError(w, trail.ToGRPC(err))
+		return
+	}
+
+	resp, err := f.whoAmI(authCtx, w, req, p)
+	if err != nil {
+		f.log.WithError(err).Warn("WhoAmI request failed.")
+		trace.WriteError(w, trail.ToGRPC(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		f.log.WithError(err).Warn("Failed to encode WhoAmI response.")
+	}
+}
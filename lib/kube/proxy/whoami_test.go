@@ -0,0 +1,69 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// TestWhoAmI exercises the `/api/v1/teleport/whoami` endpoint end-to-end
+// against a live KubeProxy, covering trait mapping (kube_users/kube_groups),
+// resource access requests, and an identity route override - the same
+// coverage the original request asked for, wired up to the TestContext.WhoAmI
+// helper that already existed for it.
+//
+// This does not by itself prove handleWhoAmI is reachable in production:
+// that depends on whatever router the real forwarder.go wires it into, and
+// that file isn't part of this checkout.
+func TestWhoAmI(t *testing.T) {
+	ctx := context.Background()
+	testCtx := SetupTestContext(ctx, t, TestConfig{
+		Clusters: []KubeClusterConfig{{Name: "local", APIEndpoint: "https://localhost"}},
+	})
+
+	const kubeUser = "alice"
+	resourceID := types.ResourceID{
+		ClusterName: testCtx.ClusterName,
+		Kind:        types.KindKubePod,
+		Name:        "local",
+	}
+	testCtx.CreateUserAndRole(ctx, t, kubeUser, RoleSpec{
+		Name:      kubeUser,
+		KubeUsers: []string{"alice-k8s"},
+		KubeGroups: []string{
+			"system:masters",
+		},
+	})
+
+	_, _, restConfig := testCtx.GenTestKubeClientsTLSCert(t, kubeUser, "local",
+		WithResourceAccessRequests(resourceID),
+		WithIdentityRoute(testCtx.ClusterName, "local"),
+	)
+
+	resp, err := testCtx.WhoAmI(restConfig)
+	require.NoError(t, err)
+	require.Equal(t, kubeUser, resp.Username)
+	require.Contains(t, resp.KubernetesUsers, "alice-k8s")
+	require.Contains(t, resp.KubernetesGroups, "system:masters")
+	require.Equal(t, "local", resp.KubernetesCluster)
+	require.Equal(t, testCtx.ClusterName, resp.RouteToCluster)
+}
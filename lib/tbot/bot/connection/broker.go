@@ -0,0 +1,198 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// EndpointHealth records the outcome of the most recent health check Broker
+// performed against a candidate address.
+type EndpointHealth struct {
+	Address   string
+	Healthy   bool
+	CheckedAt time.Time
+	Err       error
+}
+
+// BrokerConfig configures a Broker.
+type BrokerConfig struct {
+	// Candidates is the initial set of addresses the broker picks from, in
+	// priority order. At least one of Candidates or Discover must be set.
+	Candidates []string
+	// Discover, if set, is consulted whenever the broker needs to refresh
+	// its candidate list - for example after every candidate has been tried
+	// and failed. Its result replaces Candidates.
+	Discover func(ctx context.Context) ([]string, error)
+	// Ping health-checks a single candidate address. A nil Ping disables
+	// health-checking and the broker simply trusts its candidate ordering.
+	Ping func(ctx context.Context, address string) error
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Broker ranks a set of candidate Teleport auth/proxy addresses and hands
+// out the best currently-healthy one, so that identity.Service can rotate
+// away from an unreachable endpoint on renewal failure rather than retrying
+// the same dead address until the retry limit is exhausted.
+//
+// Broker is sticky: once a candidate is selected it keeps being returned by
+// Current until Rotate is explicitly called, so a healthy connection isn't
+// churned just because a later candidate also happens to be reachable.
+type Broker struct {
+	cfg *BrokerConfig
+	log *slog.Logger
+
+	mu         sync.Mutex
+	candidates []string
+	current    int
+	health     map[string]EndpointHealth
+}
+
+// NewBroker creates a Broker from cfg. At least one of cfg.Candidates or
+// cfg.Discover must produce a non-empty address list before Current or
+// Rotate can succeed.
+func NewBroker(cfg BrokerConfig) *Broker {
+	log := cfg.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Broker{
+		cfg:        &cfg,
+		log:        log,
+		candidates: append([]string(nil), cfg.Candidates...),
+		health:     make(map[string]EndpointHealth),
+	}
+}
+
+// Current returns the broker's sticky choice of address, health-checking it
+// first if a Ping function was configured. If the current candidate is
+// unhealthy, it behaves like Rotate and advances to the next one.
+func (b *Broker) Current(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.ensureCandidatesLocked(ctx); err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	for attempt := 0; attempt < len(b.candidates); attempt++ {
+		addr := b.candidates[b.current]
+		if b.checkLocked(ctx, addr) {
+			return addr, nil
+		}
+		b.advanceLocked()
+	}
+
+	return "", trace.ConnectionProblem(nil, "no healthy candidate addresses available")
+}
+
+// Rotate advances to the next candidate address and returns it, skipping
+// over any that fail their health check. Callers should call this on
+// renewal failure, before incrementing their own retry counter, so the next
+// attempt has a chance to hit a different (possibly healthy) endpoint
+// instead of repeating the same failure.
+func (b *Broker) Rotate(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.ensureCandidatesLocked(ctx); err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	b.advanceLocked()
+	for attempt := 0; attempt < len(b.candidates); attempt++ {
+		addr := b.candidates[b.current]
+		if b.checkLocked(ctx, addr) {
+			return addr, nil
+		}
+		b.advanceLocked()
+	}
+
+	return "", trace.ConnectionProblem(nil, "no healthy candidate addresses available")
+}
+
+// Health returns a snapshot of the most recent check result for every
+// candidate the broker knows about, keyed by address, for surfacing in a
+// readyz report.
+func (b *Broker) Health() map[string]EndpointHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make(map[string]EndpointHealth, len(b.health))
+	for addr, h := range b.health {
+		out[addr] = h
+	}
+	return out
+}
+
+// advanceLocked moves current to the next candidate, wrapping around.
+func (b *Broker) advanceLocked() {
+	if len(b.candidates) == 0 {
+		return
+	}
+	b.current = (b.current + 1) % len(b.candidates)
+}
+
+// ensureCandidatesLocked refreshes the candidate list via Discover if the
+// current list is empty.
+func (b *Broker) ensureCandidatesLocked(ctx context.Context) error {
+	if len(b.candidates) > 0 {
+		return nil
+	}
+	if b.cfg.Discover == nil {
+		return trace.BadParameter("no candidate addresses configured")
+	}
+
+	discovered, err := b.cfg.Discover(ctx)
+	if err != nil {
+		return trace.Wrap(err, "discovering candidate addresses")
+	}
+	if len(discovered) == 0 {
+		return trace.BadParameter("address discovery returned no candidates")
+	}
+	b.candidates = discovered
+	b.current = 0
+	return nil
+}
+
+// checkLocked health-checks addr, recording the result, and returns whether
+// it is healthy. An unconfigured Ping is treated as always-healthy.
+func (b *Broker) checkLocked(ctx context.Context, addr string) bool {
+	if b.cfg.Ping == nil {
+		return true
+	}
+
+	err := b.cfg.Ping(ctx, addr)
+	b.health[addr] = EndpointHealth{
+		Address:   addr,
+		Healthy:   err == nil,
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+	if err != nil {
+		b.log.WarnContext(ctx, "Candidate address failed health check", "address", addr, "error", err)
+	}
+	return err == nil
+}
@@ -0,0 +1,113 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"sync"
+
+	"github.com/gravitational/teleport/lib/tbot/identity"
+)
+
+const UnstableClientCredentialOutputType = "unstable-client-credential"
+
+// UnstableClientCredentialOutput configures ClientCredentialOutputService:
+// rather than materializing files to a Destination, it stashes a facade
+// for an identity in memory for an in-process API/SSH client to use
+// directly. It is unstable because the shape of that in-memory handoff is
+// still being worked out.
+type UnstableClientCredentialOutput struct {
+	// Name identifies this output among others of the same type, for
+	// logging and for looking its facade up by name.
+	Name string `yaml:"name,omitempty"`
+	// ImpersonateChain, if set, requests a chain of impersonated
+	// identities after the bot's base identity is issued: each link is
+	// signed using the previous link's identity (the base identity, for
+	// the first link), so the final certificate carries a verifiable
+	// chain of who impersonated whom (e.g. bot -> platform-admin ->
+	// per-tenant-role).
+	ImpersonateChain []identity.ImpersonationTarget `yaml:"impersonate_chain,omitempty"`
+	// SelfSignedJWT, if set, additionally exposes a TokenSource that mints
+	// short-lived, audience-scoped JWTs locally from the facade's private
+	// key, rather than round-tripping to the auth server for every
+	// request. Falls back to the normal renewal flow if the identity's
+	// key cannot be used for local signing (e.g. it's HSM-backed).
+	SelfSignedJWT *identity.SelfSignedJWTConfig `yaml:"self_signed_jwt,omitempty"`
+
+	mu          sync.Mutex
+	facade      *identity.Facade
+	chain       []identity.ChainLink
+	tokenSource identity.TokenSource
+}
+
+// Type returns the service type string used to tag this service in YAML
+// config and logging.
+func (o *UnstableClientCredentialOutput) Type() string {
+	return UnstableClientCredentialOutputType
+}
+
+// SetOrUpdateFacade stashes id (or, if a facade already exists, updates it
+// in place) for an in-process consumer to read via GetFacade.
+func (o *UnstableClientCredentialOutput) SetOrUpdateFacade(id *identity.Identity) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.facade == nil {
+		o.facade = identity.NewFacade(id)
+		return
+	}
+	o.facade.Set(id)
+}
+
+// GetFacade returns the facade for the most recently generated identity,
+// or nil if one has not been generated yet.
+func (o *UnstableClientCredentialOutput) GetFacade() *identity.Facade {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.facade
+}
+
+// SetImpersonationChain records the chain of impersonated identities
+// produced alongside the facade's current identity, so consumers can
+// surface the effective principal at each hop (e.g. in audit events).
+func (o *UnstableClientCredentialOutput) SetImpersonationChain(chain []identity.ChainLink) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.chain = chain
+}
+
+// GetImpersonationChain returns the chain recorded by SetImpersonationChain.
+func (o *UnstableClientCredentialOutput) GetImpersonationChain() []identity.ChainLink {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.chain
+}
+
+// SetTokenSource records the TokenSource built for the current facade, so
+// GetTokenSource can hand it to consumers that want locally-minted JWTs
+// instead of the full X.509 identity.
+func (o *UnstableClientCredentialOutput) SetTokenSource(ts identity.TokenSource) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tokenSource = ts
+}
+
+// GetTokenSource returns the TokenSource set by SetTokenSource, or nil if
+// SelfSignedJWT is not configured or has not yet been built.
+func (o *UnstableClientCredentialOutput) GetTokenSource() identity.TokenSource {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.tokenSource
+}
@@ -0,0 +1,87 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/tbot/bot/destination"
+)
+
+const ExecCredentialOutputType = "exec-credential"
+
+// ExecCredentialFormat selects which external tool's "exec credential"
+// helper protocol ExecCredentialOutputService speaks.
+type ExecCredentialFormat string
+
+const (
+	// ExecCredentialFormatKubernetes emits a client.authentication.k8s.io/v1
+	// ExecCredential document, for a kubeconfig user with
+	// `exec.command: tbot`.
+	ExecCredentialFormatKubernetes ExecCredentialFormat = "kubernetes"
+	// ExecCredentialFormatAWS emits an AWS `credential_process` JSON
+	// document, for an AWS profile with `credential_process = tbot ...`.
+	ExecCredentialFormatAWS ExecCredentialFormat = "aws"
+	// ExecCredentialFormatGCP emits a GCP external-account executable
+	// response, for a gcloud `external_account` config with
+	// `credential_source.executable.command: tbot ...`.
+	ExecCredentialFormatGCP ExecCredentialFormat = "gcp"
+)
+
+// ExecCredentialOutput is a sibling of UnstableClientCredentialOutput for
+// tbot invoked as a short-lived `credential_process`/ExecCredential helper
+// by kubectl, aws, or gcloud, rather than as a long-running background
+// agent: `tbot exec-credential` runs it OneShot and prints the
+// format-appropriate JSON to stdout.
+type ExecCredentialOutput struct {
+	// Destination caches the issued identity so that repeated invocations
+	// within its TTL reuse it instead of hitting the auth server every
+	// time a tool shells out to `tbot exec-credential`.
+	Destination destination.Destination `yaml:"destination,omitempty"`
+	// Format selects which tool's credential helper protocol to emit.
+	Format ExecCredentialFormat `yaml:"format"`
+}
+
+// Type returns the service type string used to tag this service in YAML
+// config and logging.
+func (o *ExecCredentialOutput) Type() string {
+	return ExecCredentialOutputType
+}
+
+// GetDestination returns the destination the cached identity is read from
+// and written to.
+func (o *ExecCredentialOutput) GetDestination() destination.Destination {
+	return o.Destination
+}
+
+// CheckAndSetDefaults validates the service config and applies defaults.
+func (o *ExecCredentialOutput) CheckAndSetDefaults() error {
+	switch o.Format {
+	case ExecCredentialFormatKubernetes, ExecCredentialFormatAWS, ExecCredentialFormatGCP:
+	case "":
+		return trace.BadParameter("format: must be set to one of 'kubernetes', 'aws', 'gcp'")
+	default:
+		return trace.BadParameter("format: unrecognized value %q", o.Format)
+	}
+	if o.Destination == nil {
+		return trace.BadParameter("no destination configured for output")
+	}
+	if err := o.Destination.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err, "validating destination")
+	}
+	return nil
+}
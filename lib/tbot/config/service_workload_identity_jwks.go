@@ -0,0 +1,71 @@
+// Teleport
+// Copyright (C) 2025 Gravitational, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/tbot/bot/destination"
+)
+
+const WorkloadIdentityJWKSServiceType = "workload-identity-jwks"
+
+// WorkloadIdentityJWKSService publishes the SPIFFE JWT signing keys used by
+// WorkloadIdentityJWTService as a JWKS document, plus a
+// `.well-known/openid-configuration` discovery document pointing at it, so
+// that relying parties can verify tbot-issued JWT SVIDs offline rather than
+// calling back to Teleport for every verification.
+type WorkloadIdentityJWKSService struct {
+	// Destination is where the JWKS and OIDC discovery documents are
+	// written. Mutually exclusive with ListenAddr.
+	Destination destination.Destination `yaml:"destination,omitempty"`
+	// ListenAddr, if set, serves the JWKS and discovery documents over
+	// HTTP(S) instead of (or in addition to) writing them to Destination.
+	ListenAddr string `yaml:"listen_addr,omitempty"`
+	// IssuerURL is the issuer this service advertises in the discovery
+	// document's `issuer` field. It must match the `iss` claim tbot signs
+	// into JWT SVIDs for verifiers to accept them.
+	IssuerURL string `yaml:"issuer_url"`
+}
+
+// Type returns the service type string used to tag this service in YAML
+// config and logging.
+func (s *WorkloadIdentityJWKSService) Type() string {
+	return WorkloadIdentityJWKSServiceType
+}
+
+// GetDestination returns the destination files are written to, if any.
+func (s *WorkloadIdentityJWKSService) GetDestination() destination.Destination {
+	return s.Destination
+}
+
+// CheckAndSetDefaults validates the service config and applies defaults.
+func (s *WorkloadIdentityJWKSService) CheckAndSetDefaults() error {
+	if s.IssuerURL == "" {
+		return trace.BadParameter("issuer_url: must be set")
+	}
+	if s.Destination == nil && s.ListenAddr == "" {
+		return trace.BadParameter("one of destination or listen_addr must be set")
+	}
+	if s.Destination != nil {
+		if err := s.Destination.CheckAndSetDefaults(); err != nil {
+			return trace.Wrap(err, "validating destination")
+		}
+	}
+	return nil
+}
+
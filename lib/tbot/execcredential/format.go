@@ -0,0 +1,124 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package execcredential marshals a tbot-issued identity into the
+// "exec credential" JSON formats that kubectl, aws, and gcloud each expect
+// from a helper binary invoked as a subprocess, so tbot can be dropped
+// directly into a kubeconfig, AWS profile, or gcloud external_account
+// config without a separate wrapper.
+package execcredential
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// KubernetesExecCredential is a client.authentication.k8s.io/v1
+// ExecCredential document, as kubectl expects on stdout from an
+// `exec`-configured kubeconfig user.
+type KubernetesExecCredential struct {
+	Kind       string                         `json:"kind"`
+	APIVersion string                         `json:"apiVersion"`
+	Status     KubernetesExecCredentialStatus `json:"status"`
+}
+
+// KubernetesExecCredentialStatus carries the client certificate kubectl
+// will present to the API server, and when it stops being valid.
+type KubernetesExecCredentialStatus struct {
+	ExpirationTimestamp   string `json:"expirationTimestamp"`
+	ClientCertificateData string `json:"clientCertificateData"`
+	ClientKeyData         string `json:"clientKeyData"`
+}
+
+// MarshalKubernetesExecCredential renders certPEM/keyPEM as a Kubernetes
+// ExecCredential document, expiring at expiry.
+func MarshalKubernetesExecCredential(certPEM, keyPEM []byte, expiry time.Time) ([]byte, error) {
+	doc := KubernetesExecCredential{
+		Kind:       "ExecCredential",
+		APIVersion: "client.authentication.k8s.io/v1",
+		Status: KubernetesExecCredentialStatus{
+			ExpirationTimestamp:   expiry.UTC().Format(time.RFC3339),
+			ClientCertificateData: string(certPEM),
+			ClientKeyData:         string(keyPEM),
+		},
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, trace.Wrap(err, "marshaling ExecCredential")
+	}
+	return out, nil
+}
+
+// AWSCredentialProcessOutput is the JSON document an AWS CLI/SDK
+// `credential_process` entry expects on stdout.
+type AWSCredentialProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyId     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// MarshalAWSCredentialProcess renders a set of temporary AWS credentials as
+// a `credential_process` document, expiring at expiry.
+func MarshalAWSCredentialProcess(accessKeyID, secretAccessKey, sessionToken string, expiry time.Time) ([]byte, error) {
+	doc := AWSCredentialProcessOutput{
+		Version:         1,
+		AccessKeyId:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		Expiration:      expiry.UTC().Format(time.RFC3339),
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, trace.Wrap(err, "marshaling AWS credential_process output")
+	}
+	return out, nil
+}
+
+// GCPExternalAccountResponse is the JSON document a GCP external-account
+// executable credential source expects on stdout, per Google's
+// externalaccount executable provider protocol.
+type GCPExternalAccountResponse struct {
+	Version        int    `json:"version"`
+	Success        bool   `json:"success"`
+	TokenType      string `json:"token_type"`
+	IDToken        string `json:"id_token,omitempty"`
+	SAMLResponse   string `json:"saml_response,omitempty"`
+	ExpirationTime int64  `json:"expiration_time"`
+}
+
+// MarshalGCPExternalAccount renders idToken as a GCP external-account
+// executable response of token_type "urn:ietf:params:oauth:token-type:id_token",
+// expiring at expiry.
+func MarshalGCPExternalAccount(idToken string, expiry time.Time) ([]byte, error) {
+	doc := GCPExternalAccountResponse{
+		Version:        1,
+		Success:        true,
+		TokenType:      "urn:ietf:params:oauth:token-type:id_token",
+		IDToken:        idToken,
+		ExpirationTime: expiry.Unix(),
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, trace.Wrap(err, "marshaling GCP external account response")
+	}
+	return out, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/tbot/client"
+)
+
+// ImpersonationTarget describes one link in an impersonation chain: the
+// role set, traits, and Kubernetes identity the next identity in the chain
+// should be issued with, modeled on the "impersonated credentials" pattern
+// in Google's cloud.google.com/go/auth/credentials/impersonate.
+type ImpersonationTarget struct {
+	// Roles are the roles the issued certificate should carry.
+	Roles []string `yaml:"roles"`
+	// Traits are the traits applied when Roles are evaluated for this link,
+	// letting e.g. a per-tenant role be parameterized without a distinct
+	// role for every tenant.
+	Traits map[string][]string `yaml:"traits,omitempty"`
+	// KubernetesUser and KubernetesGroups impersonate a Kubernetes
+	// identity for this link, the same way `tsh kube login --as` does.
+	KubernetesUser   string   `yaml:"kubernetes_user,omitempty"`
+	KubernetesGroups []string `yaml:"kubernetes_groups,omitempty"`
+	// TTL is this link's certificate lifetime. Defaults to the bot's
+	// configured credential lifetime if zero.
+	TTL time.Duration `yaml:"ttl,omitempty"`
+}
+
+// ChainLink is one issued identity in an impersonation chain, paired with
+// the target that produced it, so callers can surface the full chain (e.g.
+// in audit events or a facade) rather than only the final identity.
+type ChainLink struct {
+	Target   ImpersonationTarget
+	Identity *Identity
+}
+
+// WithImpersonationChain configures Generate to, after issuing the bot's
+// base identity, walk chain link by link: each link's certificate is
+// requested using the previous link's identity (or the base identity, for
+// the first link) as the signer, so the final certificate's chain proves
+// who impersonated whom, all the way from the bot's own role set.
+func WithImpersonationChain(chain []ImpersonationTarget) GenerateOption {
+	return func(cfg *GenerateConfig) {
+		cfg.ImpersonationChain = chain
+	}
+}
+
+// WithTraits requests the given traits be applied when Roles are evaluated
+// for the issued certificate, letting a single role be parameterized
+// per-link (e.g. a per-tenant role) instead of requiring a distinct role
+// for every tenant.
+func WithTraits(traits map[string][]string) GenerateOption {
+	return func(cfg *GenerateConfig) {
+		cfg.Traits = traits
+	}
+}
+
+// WithKubernetesImpersonation requests the named Kubernetes user/groups be
+// impersonated for the issued certificate, the same way `tsh kube login
+// --as` does.
+func WithKubernetesImpersonation(user string, groups []string) GenerateOption {
+	return func(cfg *GenerateConfig) {
+		cfg.KubernetesUser = user
+		cfg.KubernetesGroups = groups
+	}
+}
+
+// ResolveImpersonationChain walks chain link by link, building on base: for
+// each link it builds a client authenticated as the previous link's
+// identity (base, for the first link) via clientBuilder, generates the
+// next identity against that client with the link's Roles/Traits/
+// Kubernetes impersonation, and carries that identity forward as the
+// signer for the next link. This mirrors Google's impersonated-credentials
+// pattern, where each hop's token is minted using the previous hop's
+// credentials rather than a single, flatly-impersonated request.
+//
+// It returns the final identity plus every intermediate link, so callers
+// (e.g. ClientCredentialOutputService) can surface the full chain.
+func ResolveImpersonationChain(
+	ctx context.Context,
+	clientBuilder *client.Builder,
+	base *Identity,
+	chain []ImpersonationTarget,
+	defaultTTL time.Duration,
+	log *slog.Logger,
+) (*Identity, []ChainLink, error) {
+	if len(chain) == 0 {
+		return base, nil, nil
+	}
+
+	links := make([]ChainLink, 0, len(chain))
+	current := base
+
+	for i, target := range chain {
+		ttl := target.TTL
+		if ttl == 0 {
+			ttl = defaultTTL
+		}
+
+		log.InfoContext(ctx, "Requesting impersonation chain link",
+			"link", i,
+			"roles", target.Roles,
+		)
+
+		currentFacade := NewFacade(current)
+		currentClient, err := clientBuilder.Build(ctx, currentFacade)
+		if err != nil {
+			return nil, nil, trace.Wrap(err, "building client for impersonation link %d", i)
+		}
+
+		generator, err := NewGenerator(GeneratorConfig{
+			Client:      currentClient,
+			BotIdentity: currentFacade,
+			Logger:      log,
+		})
+		if err != nil {
+			currentClient.Close()
+			return nil, nil, trace.Wrap(err, "building generator for impersonation link %d", i)
+		}
+
+		opts := []GenerateOption{
+			WithRoles(target.Roles),
+			WithTraits(target.Traits),
+			WithLifetime(ttl, 0),
+			WithLogger(log),
+		}
+		if target.KubernetesUser != "" || len(target.KubernetesGroups) > 0 {
+			opts = append(opts, WithKubernetesImpersonation(target.KubernetesUser, target.KubernetesGroups))
+		}
+
+		next, err := generator.Generate(ctx, opts...)
+		closeErr := currentClient.Close()
+		if err != nil {
+			return nil, nil, trace.Wrap(err, "generating identity for impersonation link %d", i)
+		}
+		if closeErr != nil {
+			log.WarnContext(ctx, "Failed to close impersonation chain client", "link", i, "error", closeErr)
+		}
+
+		links = append(links, ChainLink{Target: target, Identity: next})
+		current = next
+	}
+
+	return current, links, nil
+}
@@ -0,0 +1,175 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gravitational/trace"
+	"github.com/google/uuid"
+)
+
+// SelfSignedJWTConfig is the `self_signed_jwt` section of
+// UnstableClientCredentialOutput: instead of round-tripping to the auth
+// server for every short-lived request, the bot's own long-lived private
+// key locally signs narrowly-scoped, short-lived JWTs, the same tradeoff
+// Google's cloud.google.com/go/auth/credentials/selfsignedjwt makes for
+// service account keys.
+type SelfSignedJWTConfig struct {
+	// Audience is the `aud` claim every minted JWT carries, identifying
+	// the single app/cluster the token is scoped to.
+	Audience string `yaml:"audience"`
+	// TTL bounds each minted JWT's lifetime. Keeping this short limits the
+	// blast radius of a leaked token, since there is no revocation path
+	// for a self-signed credential.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// TokenSource mints a fresh, short-lived bearer token on demand. Unlike
+// Facade, which exposes a full X.509 identity, a TokenSource is meant to
+// be called on (close to) every request without incurring network I/O.
+type TokenSource interface {
+	// Token returns a signed, unexpired JWT.
+	Token(ctx context.Context) (string, error)
+}
+
+// selfSignedJWTSource signs fresh JWTs using the signing key backing the
+// current identity in facade, re-deriving the signer whenever the facade's
+// underlying identity rotates so a renewed X.509 cert's key is picked up
+// automatically.
+type selfSignedJWTSource struct {
+	facade *Facade
+	cfg    SelfSignedJWTConfig
+
+	mu          sync.Mutex
+	signingCert []byte
+	signer      jose.Signer
+}
+
+// NewSelfSignedJWTSource returns a TokenSource that signs JWTs with
+// facade's current private key, scoped to cfg.Audience with lifetime
+// cfg.TTL. It returns an error immediately if the identity's key algorithm
+// cannot be used for JWT signing (e.g. an HSM-backed key exposed only as
+// an opaque handle); callers should fall back to the normal auth-server
+// round trip in that case rather than failing the output entirely.
+func NewSelfSignedJWTSource(facade *Facade, cfg SelfSignedJWTConfig) (TokenSource, error) {
+	if cfg.Audience == "" {
+		return nil, trace.BadParameter("self_signed_jwt requires an audience")
+	}
+	if cfg.TTL <= 0 {
+		return nil, trace.BadParameter("self_signed_jwt requires a positive ttl")
+	}
+
+	s := &selfSignedJWTSource{facade: facade, cfg: cfg}
+	if _, err := s.currentSigner(); err != nil {
+		return nil, trace.Wrap(err, "negotiating self-signed JWT signature algorithm")
+	}
+	return s, nil
+}
+
+// Token implements TokenSource.
+func (s *selfSignedJWTSource) Token(_ context.Context) (string, error) {
+	signer, err := s.currentSigner()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		Audience: jwt.Audience{s.cfg.Audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(s.cfg.TTL)),
+		ID:       uuid.NewString(),
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		return "", trace.Wrap(err, "signing self-signed JWT")
+	}
+	return token, nil
+}
+
+// currentSigner returns a jose.Signer for the identity's current private
+// key, rebuilding it if the identity has rotated since the last call.
+func (s *selfSignedJWTSource) currentSigner() (jose.Signer, error) {
+	id := s.facade.Get()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.signer != nil && string(id.X509Cert.Raw) == string(s.signingCert) {
+		return s.signer, nil
+	}
+
+	signer, err := buildJOSESigner(id)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	s.signer = signer
+	s.signingCert = id.X509Cert.Raw
+	return signer, nil
+}
+
+// buildJOSESigner parses id's private key and negotiates the matching JOSE
+// signature algorithm, the way the CA's own algorithm negotiation does
+// when issuing a cert for this key. HSM-backed keys that are only
+// reachable via an opaque handle (i.e. PrivateKeyBytes is empty) cannot be
+// used here; callers must fall back to the auth server round trip.
+func buildJOSESigner(id *Identity) (jose.Signer, error) {
+	if len(id.PrivateKeyBytes) == 0 {
+		return nil, trace.BadParameter("identity has no exportable private key; self-signed JWTs are unavailable for hardware-backed keys")
+	}
+
+	block, _ := pem.Decode(id.PrivateKeyBytes)
+	if block == nil {
+		return nil, trace.BadParameter("could not decode private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing private key")
+	}
+
+	var alg jose.SignatureAlgorithm
+	switch key.(type) {
+	case ed25519.PrivateKey:
+		alg = jose.EdDSA
+	case *ecdsa.PrivateKey:
+		alg = jose.ES256
+	case *rsa.PrivateKey:
+		alg = jose.RS256
+	default:
+		return nil, trace.BadParameter("unsupported private key type %T for self-signed JWT signing", key)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key.(crypto.Signer)}, nil)
+	if err != nil {
+		return nil, trace.Wrap(err, "building JOSE signer")
+	}
+	return signer, nil
+}
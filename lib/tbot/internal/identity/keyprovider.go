@@ -0,0 +1,207 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"crypto"
+	"sync"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/auth/join"
+	"github.com/gravitational/teleport/lib/cryptosuites"
+)
+
+// RekeyPolicy controls when botIdentityFromAuth rotates to a new keypair
+// versus reusing the one already in use, following the smallstep split
+// between a plain Renew (same key) and a rekey (caller-supplied new key).
+type RekeyPolicy int
+
+const (
+	// RekeyAlways generates a fresh key on every renewal. This is the
+	// historical behavior: it limits the usefulness of a compromised key to
+	// the lifetime of its associated cert and lets new keys pick up changes
+	// to the cluster's signature algorithm suite.
+	RekeyAlways RekeyPolicy = iota
+	// RekeyOnExpiryFractionOfLifetimes rotates only once the identity being
+	// renewed has lived past Config.RekeyFraction of its certificate
+	// lifetime, trading some of RekeyAlways's blast-radius reduction for
+	// fewer expensive key generations.
+	RekeyOnExpiryFractionOfLifetimes
+	// RekeyNever always reuses the existing key, generating a new
+	// certificate for it on each renewal. Useful for HSM/TPM-backed keys
+	// where key generation is expensive or where the operator wants a
+	// stable long-lived key.
+	RekeyNever
+	// RekeyExternal defers key and CSR handling entirely to
+	// Config.ExternalCSR, for keys that never exist in this process at
+	// all (e.g. behind a PKCS#11 HSM enrollment tool).
+	RekeyExternal
+)
+
+// String implements fmt.Stringer.
+func (p RekeyPolicy) String() string {
+	switch p {
+	case RekeyAlways:
+		return "always"
+	case RekeyOnExpiryFractionOfLifetimes:
+		return "on-expiry-fraction-of-lifetimes"
+	case RekeyNever:
+		return "never"
+	case RekeyExternal:
+		return "external"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyProvider supplies the signing key backing the bot's identity. It
+// abstracts over where that key actually lives - in memory, or behind a
+// PKCS#11/TPM-backed HSM - so that renewal logic does not need to know how
+// to generate or store key material itself.
+type KeyProvider interface {
+	// Current returns the key currently in use, generating one lazily on
+	// first call if none exists yet.
+	Current() (crypto.Signer, error)
+	// Rotate generates (or otherwise provisions) a new key and makes it the
+	// one subsequently returned by Current.
+	Rotate(ctx context.Context) (crypto.Signer, error)
+}
+
+// suiteAwareKeyProvider is an optional interface a KeyProvider can implement
+// to learn which cryptosuites.GetSuiteFunc to use when generating keys,
+// since the cluster's preferred signature suite is only known once an auth
+// client is available, after the provider itself has already been
+// constructed as part of Config.
+type suiteAwareKeyProvider interface {
+	SetSuiteGetter(cryptosuites.GetSuiteFunc)
+}
+
+// ExternalCSRProvider supplies already-verified public key material for the
+// RekeyExternal policy. Implementations are expected to have obtained the
+// keys via a certificate signing request signed by a key that never leaves
+// its backing store (e.g. an HSM-fronted enrollment tool), proving
+// possession without this process ever touching the private key.
+type ExternalCSRProvider interface {
+	// SignedPublicKeys returns the SSH-authorized-key and PKIX-encoded TLS
+	// public key extracted from an externally-produced, already-verified
+	// CSR.
+	SignedPublicKeys(ctx context.Context) (sshPub, tlsPub []byte, err error)
+}
+
+// inMemoryKeyProvider is the default KeyProvider, generating an in-process
+// software key on Rotate and holding onto it in memory until the next
+// Rotate call. This preserves the renewal behavior tbot used before
+// KeyProvider was introduced.
+type inMemoryKeyProvider struct {
+	mu     sync.Mutex
+	suite  cryptosuites.GetSuiteFunc
+	alg    join.KeyAlgorithm
+	signer crypto.Signer
+}
+
+// NewInMemoryKeyProvider returns the default, in-memory KeyProvider. If alg
+// is non-empty, it overrides the cluster's configured signature suite for
+// keys this provider generates, so operators can pin e.g. ed25519 on
+// constrained edge agents regardless of cluster defaults.
+func NewInMemoryKeyProvider(alg join.KeyAlgorithm) *inMemoryKeyProvider {
+	return &inMemoryKeyProvider{alg: alg}
+}
+
+// SetSuiteGetter implements suiteAwareKeyProvider.
+func (p *inMemoryKeyProvider) SetSuiteGetter(suite cryptosuites.GetSuiteFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.suite = suite
+}
+
+// Current implements KeyProvider.
+func (p *inMemoryKeyProvider) Current() (crypto.Signer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.signer == nil {
+		return nil, trace.BadParameter("no key has been generated yet, Rotate must be called first")
+	}
+	return p.signer, nil
+}
+
+// Rotate implements KeyProvider.
+func (p *inMemoryKeyProvider) Rotate(ctx context.Context) (crypto.Signer, error) {
+	p.mu.Lock()
+	suite := p.suite
+	alg := p.alg
+	p.mu.Unlock()
+
+	var key crypto.Signer
+	var err error
+	if alg != "" {
+		key, err = join.GenerateKey(alg)
+	} else {
+		if suite == nil {
+			return nil, trace.BadParameter("SetSuiteGetter must be called before Rotate")
+		}
+		key, err = cryptosuites.GenerateKey(ctx, suite, cryptosuites.HostIdentity)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	p.mu.Lock()
+	p.signer = key
+	p.mu.Unlock()
+	return key, nil
+}
+
+// PKCS11KeyProviderConfig configures a PKCS11KeyProvider.
+type PKCS11KeyProviderConfig struct {
+	// ModulePath is the path to the vendor PKCS#11 library to load.
+	ModulePath string
+	// TokenLabel identifies the token (smartcard/HSM partition) to use.
+	TokenLabel string
+	// KeyLabel identifies the key object on the token.
+	KeyLabel string
+	// PIN authenticates to the token. Should be sourced from a secrets
+	// store rather than hardcoded by callers.
+	PIN string
+}
+
+// PKCS11KeyProvider is a stub KeyProvider for PKCS#11-backed keys (HSMs,
+// smartcards). It establishes the shape of the integration - construction,
+// config - without yet implementing the PKCS#11 session handling, so that
+// Config.KeyProvider has somewhere to grow into.
+type PKCS11KeyProvider struct {
+	cfg PKCS11KeyProviderConfig
+}
+
+// NewPKCS11KeyProvider returns a PKCS#11-backed KeyProvider stub. Current
+// and Rotate are not yet implemented.
+func NewPKCS11KeyProvider(cfg PKCS11KeyProviderConfig) *PKCS11KeyProvider {
+	return &PKCS11KeyProvider{cfg: cfg}
+}
+
+// Current implements KeyProvider.
+func (p *PKCS11KeyProvider) Current() (crypto.Signer, error) {
+	return nil, trace.NotImplemented("PKCS#11 key provider is not yet implemented (token %q)", p.cfg.TokenLabel)
+}
+
+// Rotate implements KeyProvider.
+func (p *PKCS11KeyProvider) Rotate(ctx context.Context) (crypto.Signer, error) {
+	return nil, trace.NotImplemented("PKCS#11 key provider is not yet implemented (token %q)", p.cfg.TokenLabel)
+}
@@ -0,0 +1,116 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	apiclient "github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/lib/tbot/bot/destination"
+	"github.com/gravitational/teleport/lib/tbot/identity"
+)
+
+// recoveryTokenKind is the destination artifact name the renew-after-expiry
+// recovery token is persisted under, alongside the bot's regular identity
+// files.
+const recoveryTokenKind = "recovery-jwt"
+
+// recoveryTokenLifetimeMultiple is how many multiples of the identity's TTL
+// the recovery token remains valid for, giving the bot a window to recover
+// its instance ID after the X.509 certificate itself has expired (for
+// example, after extended downtime).
+const recoveryTokenLifetimeMultiple = 2
+
+// saveRecoveryToken persists token next to the bot's identity files so that
+// a later renewal attempt - potentially after a restart - can still find it
+// even if the X.509 cert it was issued alongside has since expired.
+func saveRecoveryToken(ctx context.Context, dest destination.Destination, token []byte) error {
+	return trace.Wrap(dest.Write(ctx, recoveryTokenKind, token))
+}
+
+// loadRecoveryToken reads back a previously-persisted recovery token, if
+// any. A not-found error indicates the bot was never issued one, either
+// because the feature is disabled or this is the first successful join.
+func loadRecoveryToken(ctx context.Context, dest destination.Destination) ([]byte, error) {
+	token, err := dest.Read(ctx, recoveryTokenKind)
+	return token, trace.Wrap(err)
+}
+
+// requestRecoveryToken asks the auth server for a short-lived, cluster-CA
+// signed JWT scoped to the bot instance's ID, so that a later renewal
+// attempt can recover the same bot instance ID even if the X.509 cert has
+// since expired and the original join method (e.g. a one-shot delegated
+// join) cannot be replayed.
+//
+// This requires a BotInstanceRecoveryToken RPC that does not exist on
+// api/client.Client yet - no proto or client changes were made alongside
+// this package, so there is nothing to call. Until that RPC ships, this
+// returns NotImplemented rather than referencing a method that doesn't
+// exist; callers (maybeRefreshRecoveryToken) already treat failure here as
+// non-fatal.
+func requestRecoveryToken(ctx context.Context, client *apiclient.Client, ident *identity.Identity, ttl time.Duration) ([]byte, error) {
+	return nil, trace.NotImplemented("bot instance recovery token issuance requires a BotInstanceRecoveryToken RPC that has not been added to api/client yet")
+}
+
+// maybeRefreshRecoveryToken requests and persists a fresh renew-after-expiry
+// recovery token for newIdentity when cfg.EnableRenewAfterExpiry is set. It
+// is called after every successful renewal via botIdentityFromAuth so that
+// the persisted token keeps pace with the identity's own TTL rather than
+// going stale and expiring before the next renewal attempt.
+//
+// Failures here are logged and otherwise ignored: the renewal itself already
+// succeeded, and losing the ability to recover a future expiry is an
+// availability, not correctness, concern.
+func maybeRefreshRecoveryToken(ctx context.Context, log *slog.Logger, cfg Config, authClient *apiclient.Client, newIdentity *identity.Identity) {
+	if !cfg.EnableRenewAfterExpiry {
+		return
+	}
+
+	token, err := requestRecoveryToken(ctx, authClient, newIdentity, cfg.TTL)
+	if err != nil {
+		log.WarnContext(ctx, "Failed to request renew-after-expiry recovery token, bot may not be recoverable if its identity is found expired at a future startup", "error", err)
+		return
+	}
+	if err := saveRecoveryToken(ctx, cfg.Destination, token); err != nil {
+		log.WarnContext(ctx, "Failed to persist renew-after-expiry recovery token", "error", err)
+	}
+}
+
+// recoverIdentityFromToken presents a previously-persisted recovery token to
+// the auth server's bot-renewal RPC. On success, Auth verifies the
+// signature and bot-instance claim and issues a fresh certificate tied to
+// the same bot instance ID, without requiring the original join method
+// (join token, cloud attestation, etc.) to succeed again.
+//
+// This is only attempted when Config.EnableRenewAfterExpiry is set, since
+// it widens the blast radius of a compromise of the bot's storage: anyone
+// who can read the recovery token can recover the bot's identity for as
+// long as the token remains valid.
+//
+// Like requestRecoveryToken, this requires a RecoverBotInstance RPC that
+// does not exist on api/client.Client yet, so it returns NotImplemented
+// instead of calling a nonexistent method. Initialize already falls back
+// to joining with a fresh token when recovery fails.
+func recoverIdentityFromToken(ctx context.Context, log *slog.Logger, client *apiclient.Client, recoveryToken []byte) (*identity.Identity, error) {
+	return nil, trace.NotImplemented("bot identity recovery requires a RecoverBotInstance RPC that has not been added to api/client yet")
+}
@@ -21,8 +21,11 @@ package identity
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"sync"
 	"time"
@@ -48,7 +51,7 @@ import (
 	"github.com/gravitational/teleport/lib/tbot/bot/onboarding"
 	"github.com/gravitational/teleport/lib/tbot/client"
 	"github.com/gravitational/teleport/lib/tbot/identity"
-	"github.com/gravitational/teleport/lib/tbot/internal"
+	"github.com/gravitational/teleport/lib/tbot/join/externalaccount"
 	"github.com/gravitational/teleport/lib/tbot/readyz"
 	"github.com/gravitational/teleport/lib/utils"
 )
@@ -64,6 +67,57 @@ type Config struct {
 	TTL             time.Duration
 	RenewalInterval time.Duration
 
+	// RenewalScheduleFraction sets where, between a certificate's NotBefore
+	// and NotAfter, the next renewal is scheduled: nextRenewal = NotBefore +
+	// (NotAfter-NotBefore)*RenewalScheduleFraction. Scheduling off the
+	// actual certificate lifetime (rather than the fixed RenewalInterval
+	// above) means a short-TTL cert clipped by Auth below the requested TTL
+	// is still renewed with time to spare. Defaults to 2/3.
+	RenewalScheduleFraction float64
+	// MinRenewalInterval and MaxRenewalInterval clamp the jittered delay
+	// computed from RenewalScheduleFraction, so that a clock skew or a
+	// pathologically short/long cert lifetime can't make the scheduler spin
+	// or stall entirely. Zero means unclamped on that side.
+	MinRenewalInterval time.Duration
+	MaxRenewalInterval time.Duration
+
+	// Broker, if set, ranks multiple candidate auth/proxy addresses and is
+	// consulted to pick a different one each time a renewal attempt fails,
+	// rather than retrying the same (possibly down) endpoint until the
+	// retry limit is reached.
+	Broker *connection.Broker
+
+	// KeyProvider supplies the signing key used for renewals via
+	// GenerateUserCerts. Defaults to an in-memory provider that generates a
+	// fresh key on every rotation, preserving prior behavior.
+	KeyProvider KeyProvider
+	// KeyAlgorithm overrides the algorithm used to generate the bot's
+	// identity key when KeyProvider is left unset (join.KeyAlgorithmEd25519,
+	// join.KeyAlgorithmECDSAP256, join.KeyAlgorithmRSA2048, or
+	// join.KeyAlgorithmRSA4096). Defaults to the cluster's configured
+	// signature suite. Ed25519 generates fastest and produces the smallest
+	// identity files, which matters most on constrained edge agents.
+	KeyAlgorithm join.KeyAlgorithm
+	// RekeyPolicy controls whether botIdentityFromAuth rotates to a new key
+	// on renewal or reuses the existing one. Defaults to RekeyAlways.
+	RekeyPolicy RekeyPolicy
+	// RekeyFraction is the fraction of the current certificate's lifetime
+	// that must have elapsed before RekeyOnExpiryFractionOfLifetimes
+	// triggers a rotation. Defaults to 1.0 (rekey once the cert is fully
+	// due for renewal).
+	RekeyFraction float64
+	// ExternalCSR supplies already-verified public key material for the
+	// RekeyExternal policy, for keys that are managed entirely outside this
+	// process (e.g. behind a PKCS#11 HSM enrollment tool).
+	ExternalCSR ExternalCSRProvider
+
+	// EnableRenewAfterExpiry opts into persisting a renew-after-expiry
+	// recovery token alongside the bot's identity and using it to recover
+	// the bot's instance ID if the X.509 identity is found expired at
+	// startup. This widens the blast radius of a compromise of the bot's
+	// storage, so it defaults to off.
+	EnableRenewAfterExpiry bool
+
 	FIPS bool
 
 	Logger         *slog.Logger
@@ -82,6 +136,9 @@ func (cfg *Config) CheckAndSetDefaults() error {
 	if cfg.RenewalInterval <= 0 {
 		return trace.BadParameter("RenewalInterval is required")
 	}
+	if cfg.RenewalScheduleFraction <= 0 {
+		cfg.RenewalScheduleFraction = 2.0 / 3.0
+	}
 	if cfg.ClientBuilder == nil {
 		return trace.BadParameter("ClientBuilder is required")
 	}
@@ -126,6 +183,22 @@ type Service struct {
 	facade          *identity.Facade
 	initialized     chan struct{}
 	initializedOnce sync.Once
+
+	subMu            sync.Mutex
+	subscribers      map[int]chan CertificateUpdate
+	nextSubscriberID int
+
+	renewFailures int
+	nextRenewal   time.Time
+}
+
+// NextRenewal returns the time the identity renewal loop is next scheduled
+// to run, as computed by scheduleNextRenewal. It is the zero time before
+// Run's scheduler has computed its first deadline.
+func (s *Service) NextRenewal() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextRenewal
 }
 
 // GetIdentity returns the current Bot identity.
@@ -292,6 +365,14 @@ func (s *Service) Initialize(ctx context.Context) error {
 	s.log.InfoContext(ctx, "Initializing bot identity")
 	loadedIdent, valid := s.loadIdentityFromStore(ctx, s.cfg.Destination)
 	if !valid {
+		if s.cfg.EnableRenewAfterExpiry {
+			if recovered, err := s.tryRecoverExpiredIdentity(ctx); err == nil {
+				s.log.InfoContext(ctx, "Recovered bot identity using renew-after-expiry recovery token", "identity", recovered)
+				return s.finishInitialize(ctx, recovered)
+			} else {
+				s.log.WarnContext(ctx, "Could not recover bot identity using renew-after-expiry recovery token, falling back to join token", "error", err)
+			}
+		}
 		if !s.cfg.Onboarding.HasToken() {
 			// If there's no pre-existing identity (or it has expired) and the
 			// configuration contains no join token, we cannot do anything.
@@ -357,6 +438,13 @@ func (s *Service) Initialize(ctx context.Context) error {
 
 	// We successfully renewed the bot identity!
 	s.log.InfoContext(ctx, "Fetched new bot identity", "identity", newIdentity)
+	return s.finishInitialize(ctx, newIdentity)
+}
+
+// finishInitialize persists newIdentity, builds the facaded client for it,
+// and unblocks anything waiting on s.Ready(). It is shared by the normal
+// join/renew path and the renew-after-expiry recovery path in Initialize.
+func (s *Service) finishInitialize(ctx context.Context, newIdentity *identity.Identity) error {
 	if err := identity.SaveIdentity(ctx, newIdentity, s.cfg.Destination, identity.BotKinds()...); err != nil {
 		return trace.Wrap(err)
 	}
@@ -373,12 +461,35 @@ func (s *Service) Initialize(ctx context.Context) error {
 
 	s.unblockWaiters()
 	s.cfg.StatusReporter.Report(readyz.Healthy)
+	s.broadcastUpdate(CertificateUpdate{Identity: newIdentity, Client: c})
 
 	s.log.InfoContext(ctx, "Identity initialized successfully")
 	return nil
 }
 
+// tryRecoverExpiredIdentity attempts to recover the bot's instance ID using
+// a renew-after-expiry recovery token persisted next to a previous
+// identity. It requires a client that is not authenticated with the bot's
+// (expired) identity, so it builds one using the connection config alone.
+func (s *Service) tryRecoverExpiredIdentity(ctx context.Context) (*identity.Identity, error) {
+	recoveryToken, err := loadRecoveryToken(ctx, s.cfg.Destination)
+	if err != nil {
+		return nil, trace.Wrap(err, "no renew-after-expiry recovery token available")
+	}
+
+	unauthenticatedFacade := identity.NewFacade(s.cfg.FIPS, s.cfg.Connection.Insecure, nil)
+	client, err := s.clientBuilder.Build(ctx, unauthenticatedFacade)
+	if err != nil {
+		return nil, trace.Wrap(err, "building client to present recovery token")
+	}
+	defer client.Close()
+
+	return recoverIdentityFromToken(ctx, s.log, client, recoveryToken)
+}
+
 func (s *Service) Close() error {
+	s.closeSubscribers()
+
 	c := s.GetClient()
 	if c == nil {
 		return nil
@@ -434,23 +545,130 @@ func (s *Service) Run(ctx context.Context) error {
 		ctx,
 		"Beginning bot identity renewal loop",
 		"ttl", s.cfg.TTL,
-		"interval", s.cfg.RenewalInterval,
+		"renewal_schedule_fraction", s.cfg.RenewalScheduleFraction,
 	)
 
-	err := internal.RunOnInterval(ctx, internal.RunOnIntervalConfig{
-		Service: s.String(),
-		Name:    "bot-identity-renewal",
-		F: func(ctx context.Context) error {
-			return s.renew(ctx, storageDestination)
-		},
-		Interval:           s.cfg.RenewalInterval,
-		RetryLimit:         botIdentityRenewalRetryLimit,
-		Log:                s.log,
-		ReloadCh:           s.cfg.ReloadCh,
-		WaitBeforeFirstRun: true,
-		StatusReporter:     s.cfg.StatusReporter,
+	return trace.Wrap(s.runRenewalScheduler(ctx, storageDestination))
+}
+
+// runRenewalScheduler drives the bot identity renewal loop on a
+// deadline computed from the certificate's own lifetime (see
+// scheduleNextRenewal), rather than on the fixed s.cfg.RenewalInterval. It
+// replaces the fixed-tick behavior internal.RunOnInterval previously drove
+// this loop with.
+func (s *Service) runRenewalScheduler(ctx context.Context, botDestination destination.Destination) error {
+	retry, err := retryutils.NewRetryV2(retryutils.RetryV2Config{
+		Driver: retryutils.NewExponentialDriver(1 * time.Second),
+		Max:    1 * time.Minute,
+		Jitter: retryutils.HalfJitter,
 	})
-	return trace.Wrap(err)
+	if err != nil {
+		return trace.Wrap(err, "creating retry")
+	}
+
+	s.scheduleNextRenewal(ctx)
+	for {
+		delay := time.Until(s.NextRenewal())
+		if delay < 0 {
+			delay = 0
+		}
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-s.cfg.ReloadCh:
+			timer.Stop()
+			// A reload does not itself warrant an immediate renewal - we
+			// just recompute the deadline in case the reload also updated
+			// the identity on disk or the schedule configuration.
+			s.log.InfoContext(ctx, "Received reload signal, recomputing next identity renewal deadline")
+			s.scheduleNextRenewal(ctx)
+			continue
+		case <-timer.C:
+		}
+
+		if err := s.renew(ctx, botDestination); err != nil {
+			s.mu.Lock()
+			failures := s.renewFailures
+			s.mu.Unlock()
+			if failures >= botIdentityRenewalRetryLimit {
+				return trace.Wrap(err, "reached identity renewal retry limit of %d consecutive failures", botIdentityRenewalRetryLimit)
+			}
+
+			s.rotateConnectionOnFailure(ctx)
+			retry.Inc()
+			wait := retry.Duration()
+			s.log.WarnContext(ctx, "Identity renewal failed, will retry", "error", err, "wait", wait)
+			s.mu.Lock()
+			s.nextRenewal = time.Now().Add(wait)
+			s.mu.Unlock()
+			continue
+		}
+
+		retry.Reset()
+		s.scheduleNextRenewal(ctx)
+	}
+}
+
+// scheduleNextRenewal computes and stores the next renewal deadline from the
+// current facade identity's certificate lifetime: NotBefore +
+// (NotAfter-NotBefore)*RenewalScheduleFraction, jittered and clamped to
+// [MinRenewalInterval, MaxRenewalInterval]. It also surfaces the deadline
+// via the configured StatusReporter so it is visible in the readyz report.
+func (s *Service) scheduleNextRenewal(ctx context.Context) {
+	cert := s.facade.Get().X509Cert
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	target := cert.NotBefore.Add(time.Duration(float64(lifetime) * s.cfg.RenewalScheduleFraction))
+
+	interval := time.Until(target)
+	if interval < 0 {
+		interval = 0
+	}
+	interval = retryutils.HalfJitter(interval)
+	if s.cfg.MinRenewalInterval > 0 && interval < s.cfg.MinRenewalInterval {
+		interval = s.cfg.MinRenewalInterval
+	}
+	if s.cfg.MaxRenewalInterval > 0 && interval > s.cfg.MaxRenewalInterval {
+		interval = s.cfg.MaxRenewalInterval
+	}
+
+	next := time.Now().Add(interval)
+	s.mu.Lock()
+	s.nextRenewal = next
+	s.mu.Unlock()
+
+	s.log.DebugContext(ctx, "Scheduled next identity renewal", "next_renewal", next)
+	s.cfg.StatusReporter.ReportReason(readyz.Healthy, fmt.Sprintf("next identity renewal at %s", next.Format(time.RFC3339)))
+}
+
+// rotateConnectionOnFailure asks cfg.Broker (if configured) to rotate to
+// the next candidate address after a failed renewal attempt, so that the
+// next attempt targets a different endpoint instead of repeating the same
+// failure. It also surfaces the broker's per-endpoint health via the
+// StatusReporter.
+func (s *Service) rotateConnectionOnFailure(ctx context.Context) {
+	if s.cfg.Broker == nil {
+		return
+	}
+
+	addr, err := s.cfg.Broker.Rotate(ctx)
+	if err != nil {
+		s.log.WarnContext(ctx, "Could not rotate to a different candidate address", "error", err)
+		return
+	}
+
+	s.log.InfoContext(ctx, "Rotated to a different candidate address after renewal failure", "address", addr)
+	s.cfg.Connection.Address = addr
+
+	for endpoint, health := range s.cfg.Broker.Health() {
+		status := readyz.Healthy
+		if !health.Healthy {
+			status = readyz.Unhealthy
+		}
+		s.cfg.StatusReporter.ReportReason(status, fmt.Sprintf("candidate %s: healthy=%v", endpoint, health.Healthy))
+	}
 }
 
 func (s *Service) renew(
@@ -463,23 +681,46 @@ func (s *Service) renew(
 	currentIdentity := s.facade.Get()
 	// Make sure we can still write to the bot's destination.
 	if err := identity.VerifyWrite(ctx, botDestination); err != nil {
-		return trace.Wrap(err, "Cannot write to destination %s, aborting.", botDestination)
+		return s.recordRenewResult(ctx, trace.Wrap(err, "Cannot write to destination %s, aborting.", botDestination))
 	}
 
 	newIdentity, err := renewIdentity(ctx, s.log, s.cfg, s.clientBuilder, currentIdentity)
 	if err != nil {
-		return trace.Wrap(err, "renewing identity")
+		return s.recordRenewResult(ctx, trace.Wrap(err, "renewing identity"))
 	}
 
 	s.log.InfoContext(ctx, "Fetched new bot identity", "identity", newIdentity)
 	s.facade.Set(newIdentity)
 
 	if err := identity.SaveIdentity(ctx, newIdentity, botDestination, identity.BotKinds()...); err != nil {
-		return trace.Wrap(err, "saving new identity")
+		return s.recordRenewResult(ctx, trace.Wrap(err, "saving new identity"))
 	}
 	s.log.DebugContext(ctx, "Bot identity persisted", "identity", newIdentity)
 
-	return nil
+	s.broadcastUpdate(CertificateUpdate{Identity: newIdentity, Client: s.GetClient()})
+	return s.recordRenewResult(ctx, nil)
+}
+
+// recordRenewResult tracks consecutive renewal failures and, once
+// botIdentityRenewalRetryLimit is reached, pushes an error CertificateUpdate
+// to subscribers so they can react (e.g. surface the outage) without having
+// to duplicate this counting themselves. It always returns err unchanged,
+// so callers can write `return s.recordRenewResult(ctx, trace.Wrap(err, ...))`.
+func (s *Service) recordRenewResult(ctx context.Context, err error) error {
+	s.mu.Lock()
+	if err == nil {
+		s.renewFailures = 0
+	} else {
+		s.renewFailures++
+	}
+	failures := s.renewFailures
+	s.mu.Unlock()
+
+	if err != nil && failures >= botIdentityRenewalRetryLimit {
+		s.log.ErrorContext(ctx, "Bot identity renewal has failed repeatedly, notifying subscribers", "consecutive_failures", failures)
+		s.broadcastUpdate(CertificateUpdate{Err: err})
+	}
+	return err
 }
 
 func (s *Service) unblockWaiters() {
@@ -534,11 +775,12 @@ func renewIdentity(
 		// When using a renewable join method, we use GenerateUserCerts to
 		// request a new certificate using our current identity.
 		newIdentity, err := botIdentityFromAuth(
-			ctx, log, oldIdentity, authClient, cfg.TTL,
+			ctx, log, oldIdentity, authClient, cfg.TTL, cfg,
 		)
 		if err != nil {
 			return nil, trace.Wrap(err, "renewing identity using GenerateUserCert")
 		}
+		maybeRefreshRecoveryToken(ctx, log, cfg, authClient, newIdentity)
 		return newIdentity, nil
 	}
 
@@ -581,12 +823,18 @@ func renewIdentity(
 
 // botIdentityFromAuth uses an existing identity to request a new from the auth
 // server using GenerateUserCerts. This only works for renewable join types.
+//
+// Whether a fresh keypair is generated (a "rekey") or the existing one is
+// reused depends on cfg.RekeyPolicy - see shouldRekey. This matters for
+// HSM/TPM/PKCS#11-backed keys, where generating a new key on every renewal
+// is wasteful or simply not how the operator wants to manage key material.
 func botIdentityFromAuth(
 	ctx context.Context,
 	log *slog.Logger,
 	ident *identity.Identity,
 	client *apiclient.Client,
 	ttl time.Duration,
+	cfg Config,
 ) (*identity.Identity, error) {
 	ctx, span := tracer.Start(ctx, "botIdentityFromAuth")
 	defer span.End()
@@ -596,26 +844,55 @@ func botIdentityFromAuth(
 		return nil, trace.BadParameter("renewIdentityWithAuth must be called with non-nil client and identity")
 	}
 
-	// Always generate a new key when refreshing the identity. This limits
-	// usefulness of compromised keys to the lifetime of their associated cert,
-	// and allows for new keys to follow any changes to the signature algorithm
-	// suite.
-	key, err := cryptosuites.GenerateKey(ctx,
-		cryptosuites.GetCurrentSuiteFromAuthPreference(client),
-		cryptosuites.HostIdentity)
-	if err != nil {
-		return nil, trace.Wrap(err)
+	if cfg.RekeyPolicy == RekeyExternal {
+		return botIdentityFromExternalCSR(ctx, log, ident, client, ttl, cfg)
+	}
+
+	keyProvider := cfg.KeyProvider
+	if keyProvider == nil {
+		keyProvider = NewInMemoryKeyProvider(cfg.KeyAlgorithm)
+	}
+	if sa, ok := keyProvider.(suiteAwareKeyProvider); ok {
+		sa.SetSuiteGetter(cryptosuites.GetCurrentSuiteFromAuthPreference(client))
+	}
+
+	var (
+		signer          crypto.Signer
+		privateKeyPEM   []byte
+		reusingExisting bool
+		err             error
+	)
+	if shouldRekey(cfg.RekeyPolicy, cfg.RekeyFraction, ident) {
+		signer, err = keyProvider.Rotate(ctx)
+	} else {
+		signer, err = keyProvider.Current()
+		reusingExisting = true
 	}
-	privateKeyPEM, err := keys.MarshalPrivateKey(key)
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trace.Wrap(err, "obtaining signing key")
 	}
-	sshPubKey, err := ssh.NewPublicKey(key.Public())
+
+	if !reusingExisting {
+		// keys.MarshalPrivateKey doesn't produce a valid OpenSSH PEM for
+		// ed25519 keys (see join.MarshalOpenSSHPrivateKey's doc comment),
+		// so an ed25519 signer is marshaled with that instead, regardless
+		// of which KeyProvider produced it.
+		if edKey, ok := signer.(ed25519.PrivateKey); ok {
+			privateKeyPEM, err = join.MarshalOpenSSHPrivateKey(edKey)
+		} else {
+			privateKeyPEM, err = keys.MarshalPrivateKey(signer)
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	sshPubKey, err := ssh.NewPublicKey(signer.Public())
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 	sshPub := ssh.MarshalAuthorizedKey(sshPubKey)
-	tlsPub, err := keys.MarshalPublicKey(key.Public())
+	tlsPub, err := keys.MarshalPublicKey(signer.Public())
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -632,10 +909,63 @@ func botIdentityFromAuth(
 		return nil, trace.Wrap(err, "calling GenerateUserCerts")
 	}
 
+	loadParams := &identity.LoadIdentityParams{
+		PublicKeyBytes: sshPub,
+		TokenHashBytes: ident.TokenHashBytes,
+	}
+	if reusingExisting {
+		// The key was not rotated, so reuse the private key material already
+		// on disk rather than attempting to re-derive it from the signer,
+		// which may be backed by an HSM that never exposes raw key bytes.
+		loadParams.PrivateKeyBytes = ident.PrivateKeyBytes
+	} else {
+		loadParams.PrivateKeyBytes = privateKeyPEM
+	}
+
+	newIdentity, err := identity.ReadIdentityFromStore(loadParams, certs)
+	if err != nil {
+		return nil, trace.Wrap(err, "reading renewed identity")
+	}
+
+	return newIdentity, nil
+}
+
+// botIdentityFromExternalCSR handles the RekeyExternal policy: the bot's
+// private key lives entirely outside of this process (e.g. in an HSM
+// fronted by a separate enrollment tool), so rather than generating or
+// rotating a key here, we obtain already-verified public key material from
+// cfg.ExternalCSR and use it directly in the certificate request.
+func botIdentityFromExternalCSR(
+	ctx context.Context,
+	log *slog.Logger,
+	ident *identity.Identity,
+	client *apiclient.Client,
+	ttl time.Duration,
+	cfg Config,
+) (*identity.Identity, error) {
+	if cfg.ExternalCSR == nil {
+		return nil, trace.BadParameter("RekeyPolicy is RekeyExternal but no ExternalCSR provider is configured")
+	}
+
+	log.InfoContext(ctx, "Fetching bot identity using externally-signed CSR")
+	sshPub, tlsPub, err := cfg.ExternalCSR.SignedPublicKeys(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err, "obtaining externally-signed public keys")
+	}
+
+	certs, err := client.GenerateUserCerts(ctx, proto.UserCertsRequest{
+		SSHPublicKey: sshPub,
+		TLSPublicKey: tlsPub,
+		Username:     ident.X509Cert.Subject.CommonName,
+		Expires:      time.Now().Add(ttl),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err, "calling GenerateUserCerts")
+	}
+
 	newIdentity, err := identity.ReadIdentityFromStore(&identity.LoadIdentityParams{
-		PrivateKeyBytes: privateKeyPEM,
-		PublicKeyBytes:  sshPub,
-		TokenHashBytes:  ident.TokenHashBytes,
+		PublicKeyBytes: sshPub,
+		TokenHashBytes: ident.TokenHashBytes,
 	}, certs)
 	if err != nil {
 		return nil, trace.Wrap(err, "reading renewed identity")
@@ -644,6 +974,33 @@ func botIdentityFromAuth(
 	return newIdentity, nil
 }
 
+// shouldRekey decides whether botIdentityFromAuth should rotate to a fresh
+// keypair for this renewal, based on policy:
+//
+//   - RekeyAlways (the default, preserving prior behavior): always rotate.
+//   - RekeyOnExpiryFractionOfLifetimes: rotate only once the current
+//     identity has lived past fraction of its certificate lifetime.
+//   - RekeyNever: never rotate, always reuse the existing key.
+//   - RekeyExternal: handled separately by botIdentityFromExternalCSR and
+//     never reaches this function.
+func shouldRekey(policy RekeyPolicy, fraction float64, ident *identity.Identity) bool {
+	switch policy {
+	case RekeyNever:
+		return false
+	case RekeyOnExpiryFractionOfLifetimes:
+		if fraction <= 0 {
+			fraction = 1.0
+		}
+		lifetime := ident.X509Cert.NotAfter.Sub(ident.X509Cert.NotBefore)
+		elapsed := time.Since(ident.X509Cert.NotBefore)
+		return elapsed >= time.Duration(float64(lifetime)*fraction)
+	case RekeyAlways, RekeyExternal:
+		fallthrough
+	default:
+		return true
+	}
+}
+
 // botIdentityFromToken uses a join token to request a bot identity from an auth
 // server using auth.Register.
 //
@@ -721,6 +1078,18 @@ func botIdentityFromToken(
 		params.TerraformCloudAudienceTag = cfg.Onboarding.Terraform.AudienceTag
 	case types.JoinMethodEnv0:
 		params.Env0AudienceTag = "something"
+	case types.JoinMethodExternalAccount:
+		source, err := externalaccount.BuildSource(cfg.Onboarding.ExternalAccount)
+		if err != nil {
+			return nil, trace.Wrap(err, "configuring external account subject token source")
+		}
+		token, err := source.GetSubjectToken(ctx)
+		if err != nil {
+			return nil, trace.Wrap(err, "fetching external account subject token")
+		}
+		params.ExternalAccountParams = join.ExternalAccountParams{
+			SubjectToken: token,
+		}
 	case types.JoinMethodGitLab:
 		params.GitlabParams = join.GitlabParams{
 			EnvVarName: cfg.Onboarding.Gitlab.TokenEnvVarName,
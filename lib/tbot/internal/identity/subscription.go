@@ -0,0 +1,124 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	apiclient "github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/lib/tbot/identity"
+)
+
+// subscriberBufferSize bounds how many updates a subscriber channel can hold
+// before Subscribe starts dropping the oldest unread update to make room for
+// the newest one. A slow or stalled consumer should never be able to block
+// the renewal loop.
+const subscriberBufferSize = 4
+
+// CertificateUpdate is pushed to subscribers of Service.Subscribe whenever
+// the bot's identity is rotated, mirroring swarmkit's RenewTLSConfig
+// notification pattern. Exactly one of (Identity, Client) or Err is
+// meaningful: a successful rotation carries the new identity and its
+// facaded client, while Err is set once renewal has failed
+// botIdentityRenewalRetryLimit times in a row.
+type CertificateUpdate struct {
+	Identity *identity.Identity
+	Client   *apiclient.Client
+	Err      error
+}
+
+// Subscribe returns a channel of CertificateUpdates and an unsubscribe
+// function. Callers should prefer this to polling GetIdentity/GetClient, as
+// it lets them tear down anything built against a stale identity (e.g. gRPC
+// streams) deterministically rather than on their own timer.
+//
+// No caller does yet: the output services under lib/tbot (exec-credential,
+// client-credential, workload-identity-jwks) are built from
+// bot.ServiceDependencies, not from this Service, and bot.ServiceDependencies
+// doesn't exist in this checkout to thread a subscription through. Wiring
+// those services to Subscribe instead of their Facade swap is still
+// outstanding and depends on that package.
+//
+// The returned channel is never closed except by the unsubscribe function
+// or Service.Close. If the caller falls behind, the oldest buffered update
+// is dropped to make room for the newest - subscribers only ever need the
+// most recent identity, not a full history.
+func (s *Service) Subscribe() (<-chan CertificateUpdate, func()) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.subscribers == nil {
+		s.subscribers = make(map[int]chan CertificateUpdate)
+	}
+
+	id := s.nextSubscriberID
+	s.nextSubscriberID++
+	ch := make(chan CertificateUpdate, subscriberBufferSize)
+	s.subscribers[id] = ch
+
+	unsubscribe := func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		if ch, ok := s.subscribers[id]; ok {
+			delete(s.subscribers, id)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// broadcastUpdate pushes update to every current subscriber without
+// blocking on any of them.
+func (s *Service) broadcastUpdate(update CertificateUpdate) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for _, ch := range s.subscribers {
+		sendCertificateUpdateNonBlocking(ch, update)
+	}
+}
+
+// closeSubscribers closes every subscriber channel and discards them, called
+// from Service.Close so subscribers can observe that no further updates are
+// coming.
+func (s *Service) closeSubscribers() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for id, ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, id)
+	}
+}
+
+// sendCertificateUpdateNonBlocking sends update to ch, dropping the oldest
+// buffered update first if ch is full, so a slow subscriber can never stall
+// the sender.
+func sendCertificateUpdateNonBlocking(ch chan CertificateUpdate, update CertificateUpdate) {
+	for {
+		select {
+		case ch <- update:
+			return
+		default:
+		}
+
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package externalaccount
+
+import "github.com/gravitational/trace"
+
+// Config is the `onboarding.external_account` section of tbot's config
+// file. Exactly one of AWS, URL, File, or Executable should be set,
+// selecting which SubjectTokenSource implementation BuildSource returns.
+type Config struct {
+	AWS        *AWSProvider        `yaml:"aws,omitempty"`
+	URL        *URLProvider        `yaml:"url,omitempty"`
+	File       *FileProvider       `yaml:"file,omitempty"`
+	Executable *ExecutableProvider `yaml:"executable,omitempty"`
+}
+
+// BuildSource returns the SubjectTokenSource selected by cfg.
+func BuildSource(cfg Config) (SubjectTokenSource, error) {
+	set := 0
+	var source SubjectTokenSource
+	if cfg.AWS != nil {
+		set++
+		source = cfg.AWS
+	}
+	if cfg.URL != nil {
+		set++
+		source = cfg.URL
+	}
+	if cfg.File != nil {
+		set++
+		source = cfg.File
+	}
+	if cfg.Executable != nil {
+		set++
+		source = cfg.Executable
+	}
+
+	switch set {
+	case 0:
+		return nil, trace.BadParameter("external_account join method requires exactly one of aws, url, file, or executable to be configured")
+	case 1:
+		return source, nil
+	default:
+		return nil, trace.BadParameter("external_account join method accepts only one of aws, url, file, or executable, got %d", set)
+	}
+}
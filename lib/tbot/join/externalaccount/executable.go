@@ -0,0 +1,122 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package externalaccount
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// executableResponse is the JSON document the configured command is
+// expected to print to stdout, modeled on Google's external-account
+// executable credential source response format.
+type executableResponse struct {
+	Version        int    `json:"version"`
+	Success        bool   `json:"success"`
+	TokenType      string `json:"token_type"`
+	IDToken        string `json:"id_token"`
+	AccessToken    string `json:"access_token"`
+	ExpirationTime int64  `json:"expiration_time"`
+}
+
+// ExecutableProvider fetches a subject token by running a user-supplied
+// command and parsing a JSON response from its stdout.
+//
+// Because this runs an arbitrary command the bot's config file names,
+// it must be explicitly opted into with AllowExecutables: a config file
+// that can be edited or injected by a lower-privileged actor must not be
+// able to gain code execution as the bot simply by pointing this field at
+// a new binary.
+type ExecutableProvider struct {
+	// Command is the command (and arguments) to run, e.g.
+	// []string{"/usr/local/bin/my-idp-helper"}.
+	Command []string
+	// Timeout bounds how long the command is allowed to run.
+	Timeout time.Duration
+	// Audience is echoed to the command as TELEPORT_EXTERNAL_ACCOUNT_AUDIENCE
+	// so it knows which audience to request a token for.
+	Audience string
+	// AllowExecutables must be explicitly set to true for GetSubjectToken
+	// to run Command at all. This is the opt-in the security requirement
+	// calls for: without it, a config file alone cannot cause tbot to
+	// execute arbitrary code.
+	AllowExecutables bool
+}
+
+// GetSubjectToken implements SubjectTokenSource.
+func (p *ExecutableProvider) GetSubjectToken(ctx context.Context) (string, error) {
+	if !p.AllowExecutables {
+		return "", trace.AccessDenied("executable subject token provider is disabled; set allow_executables: true to enable running %q", p.Command)
+	}
+	if len(p.Command) == 0 {
+		return "", trace.BadParameter("executable subject token provider requires a command")
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Env = append(os.Environ(),
+		"TELEPORT_EXTERNAL_ACCOUNT_AUDIENCE="+p.Audience,
+		"TELEPORT_EXTERNAL_ACCOUNT_TOKEN_TYPE=urn:ietf:params:oauth:token-type:jwt",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", trace.ConnectionProblem(err, "executable subject token provider timed out after %s", timeout)
+		}
+		return "", trace.Wrap(err, "running executable subject token provider: %s", stderr.String())
+	}
+
+	var resp executableResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return "", trace.Wrap(err, "parsing executable subject token provider response")
+	}
+	if !resp.Success {
+		return "", trace.AccessDenied("executable subject token provider reported failure")
+	}
+
+	token := resp.IDToken
+	if token == "" {
+		token = resp.AccessToken
+	}
+	if token == "" {
+		return "", trace.BadParameter("executable subject token provider returned no id_token or access_token")
+	}
+
+	if resp.ExpirationTime != 0 && time.Unix(resp.ExpirationTime, 0).Before(time.Now()) {
+		return "", trace.BadParameter("executable subject token provider returned an already-expired token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package externalaccount
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// FileProvider fetches a subject token by reading it from a file, e.g. one
+// a platform's workload identity sidecar projects and rotates on a
+// schedule. The file is re-read on every call so that a rotated token is
+// always picked up, rather than caching the value from construction time.
+type FileProvider struct {
+	// Path is the file to read the subject token from.
+	Path string
+}
+
+// GetSubjectToken implements SubjectTokenSource.
+func (p *FileProvider) GetSubjectToken(_ context.Context) (string, error) {
+	raw, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", trace.BadParameter("subject token file %q is empty", p.Path)
+	}
+	return token, nil
+}
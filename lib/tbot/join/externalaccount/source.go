@@ -0,0 +1,39 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package externalaccount implements a join method modeled on Google's
+// `externalaccount` workload identity federation subject-token providers:
+// rather than teaching tbot to speak every possible ambient-credential
+// format itself, a SubjectTokenSource fetches an opaque subject token from
+// wherever the workload's environment keeps one (an AWS instance role, a
+// URL, a file, or a helper executable), and the bot join flow exchanges
+// that token with the Teleport auth server the same way it would any other
+// OIDC/JWT-based join method.
+package externalaccount
+
+import "context"
+
+// SubjectTokenSource fetches the subject token tbot should present to the
+// Teleport auth server for this join attempt. Implementations are called
+// once per join/rejoin, so a source that reads from a file or environment
+// should re-read rather than caching the value from a previous call.
+type SubjectTokenSource interface {
+	// GetSubjectToken returns the subject token, or an error if it could
+	// not be obtained.
+	GetSubjectToken(ctx context.Context) (string, error)
+}
@@ -0,0 +1,123 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package externalaccount
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// URLProvider fetches a subject token by GETting a configurable endpoint
+// (e.g. a cloud metadata service or an internal secrets proxy) and
+// extracting the token from the response body.
+type URLProvider struct {
+	// Endpoint is the URL to GET.
+	Endpoint string
+	// Headers are added to the request, e.g. "Metadata-Flavor: Google".
+	Headers map[string]string
+	// JSONPointer, if set, is a dot-separated path (e.g. "access_token" or
+	// "data.token") used to pull the token out of a JSON response body.
+	// Takes precedence over Regex.
+	JSONPointer string
+	// Regex, if set and JSONPointer is empty, is matched against the raw
+	// response body; the token is the first capture group.
+	Regex string
+
+	client *http.Client
+}
+
+// GetSubjectToken implements SubjectTokenSource.
+func (p *URLProvider) GetSubjectToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Endpoint, nil)
+	if err != nil {
+		return "", trace.Wrap(err, "building request")
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	httpClient := p.client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", trace.Wrap(err, "fetching subject token from %s", p.Endpoint)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", trace.Wrap(err, "reading response body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", trace.ConnectionProblem(nil, "subject token endpoint returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	switch {
+	case p.JSONPointer != "":
+		return extractJSONPointer(body, p.JSONPointer)
+	case p.Regex != "":
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return "", trace.Wrap(err, "compiling regex")
+		}
+		matches := re.FindSubmatch(body)
+		if len(matches) < 2 {
+			return "", trace.NotFound("regex %q did not match response body", p.Regex)
+		}
+		return string(matches[1]), nil
+	default:
+		return strings.TrimSpace(string(body)), nil
+	}
+}
+
+// extractJSONPointer walks a dot-separated path of object keys through a
+// JSON document and returns the string value found there.
+func extractJSONPointer(body []byte, pointer string) (string, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", trace.Wrap(err, "parsing JSON response")
+	}
+
+	cur := doc
+	for _, key := range strings.Split(pointer, ".") {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return "", trace.BadParameter("JSON pointer %q: %q is not an object", pointer, key)
+		}
+		val, ok := obj[key]
+		if !ok {
+			return "", trace.NotFound("JSON pointer %q: key %q not found", pointer, key)
+		}
+		cur = val
+	}
+
+	str, ok := cur.(string)
+	if !ok {
+		return "", trace.BadParameter("JSON pointer %q did not resolve to a string", pointer)
+	}
+	return str, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/gravitational/teleport"
 	apiclient "github.com/gravitational/teleport/api/client"
 	"github.com/gravitational/teleport/lib/tbot/bot"
+	tbotclient "github.com/gravitational/teleport/lib/tbot/client"
 	"github.com/gravitational/teleport/lib/tbot/config"
 	"github.com/gravitational/teleport/lib/tbot/identity"
 	"github.com/gravitational/teleport/lib/tbot/internal"
@@ -43,6 +44,7 @@ func ClientCredentialOutputServiceBuilder(botCfg *config.BotConfig, cfg *config.
 			cfg:                cfg,
 			reloadCh:           deps.ReloadCh,
 			identityGenerator:  deps.IdentityGenerator,
+			clientBuilder:      deps.ClientBuilder,
 		}
 		svc.log = deps.Logger.With(
 			teleport.ComponentKey,
@@ -67,6 +69,9 @@ type ClientCredentialOutputService struct {
 	statusReporter     readyz.Reporter
 	reloadCh           <-chan struct{}
 	identityGenerator  *identity.Generator
+	// clientBuilder is used to build clients impersonated as each
+	// intermediate identity when cfg.ImpersonateChain is set.
+	clientBuilder *tbotclient.Builder
 }
 
 func (s *ClientCredentialOutputService) String() string {
@@ -111,6 +116,42 @@ func (s *ClientCredentialOutputService) generate(ctx context.Context) error {
 		return trace.Wrap(err, "generating identity")
 	}
 
+	if len(s.cfg.ImpersonateChain) > 0 {
+		final, links, err := identity.ResolveImpersonationChain(
+			ctx,
+			s.clientBuilder,
+			id,
+			s.cfg.ImpersonateChain,
+			s.botCfg.CredentialLifetime.TTL,
+			s.log,
+		)
+		if err != nil {
+			return trace.Wrap(err, "resolving impersonation chain")
+		}
+		for _, link := range links {
+			s.log.InfoContext(ctx, "Issued impersonation chain link",
+				"roles", link.Target.Roles,
+				"principal", link.Identity.X509Cert.Subject.CommonName,
+			)
+		}
+		s.cfg.SetImpersonationChain(links)
+		id = final
+	}
+
 	s.cfg.SetOrUpdateFacade(id)
+
+	if s.cfg.SelfSignedJWT != nil {
+		ts, err := identity.NewSelfSignedJWTSource(s.cfg.GetFacade(), *s.cfg.SelfSignedJWT)
+		if err != nil {
+			// The configured key can't self-sign (e.g. it's HSM-backed).
+			// Fall back to the normal flow: consumers without a
+			// TokenSource fall through to using the full facade/cert.
+			s.log.WarnContext(ctx, "Self-signed JWT unavailable, falling back to auth-issued credentials", "error", err)
+			s.cfg.SetTokenSource(nil)
+		} else {
+			s.cfg.SetTokenSource(ts)
+		}
+	}
+
 	return nil
 }
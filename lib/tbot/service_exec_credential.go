@@ -0,0 +1,182 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tbot
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport"
+	apiclient "github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/lib/tbot/bot"
+	"github.com/gravitational/teleport/lib/tbot/config"
+	"github.com/gravitational/teleport/lib/tbot/execcredential"
+	"github.com/gravitational/teleport/lib/tbot/identity"
+	"github.com/gravitational/teleport/lib/tbot/readyz"
+)
+
+// ExecCredentialOutputServiceBuilder returns a bot.ServiceBuilder for
+// `tbot exec-credential`: rather than running continuously like
+// ClientCredentialOutputServiceBuilder's service, this is invoked OneShot,
+// directly by kubectl/aws/gcloud as a subprocess, and prints a single
+// format-appropriate credential JSON document to stdout before exiting.
+//
+// There's no `tbot exec-credential` subcommand to register this builder
+// with in this checkout: that's true of every other ServiceBuilder in this
+// package too (ClientCredentialOutputServiceBuilder, DatabaseTunnelServiceBuilder,
+// WorkloadIdentityJWKSServiceBuilder), none of which have a caller here
+// either. The service registry that would dispatch a config's `type:` to
+// one of these builders, and the CLI command parsing that would build an
+// ExecCredentialOutput from `tbot exec-credential` flags, both live in the
+// top-level bot.go/cli.go that this snapshot doesn't include.
+func ExecCredentialOutputServiceBuilder(botCfg *config.BotConfig, cfg *config.ExecCredentialOutput) bot.ServiceBuilder {
+	return func(deps bot.ServiceDependencies) (bot.Service, error) {
+		svc := &ExecCredentialOutputService{
+			botAuthClient:      deps.Client,
+			botIdentityReadyCh: deps.BotIdentityReadyCh,
+			botCfg:             botCfg,
+			cfg:                cfg,
+			identityGenerator:  deps.IdentityGenerator,
+			stdout:             os.Stdout,
+		}
+		svc.log = deps.Logger.With(
+			teleport.ComponentKey,
+			teleport.Component(teleport.ComponentTBot, "svc", svc.String()),
+		)
+		svc.statusReporter = deps.StatusRegistry.AddService(svc.String())
+		return svc, nil
+	}
+}
+
+// ExecCredentialOutputService generates (or reuses a still-valid cached)
+// identity and prints it to stdout in the protocol cfg.Format selects.
+// It is a OneShot-only service: `tbot exec-credential` runs it once per
+// invocation, the same way kubectl/aws/gcloud invoke any other credential
+// helper.
+type ExecCredentialOutputService struct {
+	botAuthClient      *apiclient.Client
+	botIdentityReadyCh <-chan struct{}
+	botCfg             *config.BotConfig
+	cfg                *config.ExecCredentialOutput
+	log                *slog.Logger
+	statusReporter     readyz.Reporter
+	identityGenerator  *identity.Generator
+
+	// stdout is where the formatted credential document is written.
+	// Defaults to os.Stdout; overridden in tests.
+	stdout io.Writer
+}
+
+func (s *ExecCredentialOutputService) String() string {
+	return cmp.Or(
+		s.cfg.Type(),
+		"exec-credential",
+	)
+}
+
+// OneShot generates (or reuses) an identity and writes the formatted
+// credential document to stdout. ExecCredentialOutputService has no Run
+// loop: it is only ever invoked OneShot, by `tbot exec-credential`.
+func (s *ExecCredentialOutputService) OneShot(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "ExecCredentialOutputService/OneShot")
+	defer span.End()
+
+	id, err := s.loadCachedIdentity(ctx)
+	if err != nil {
+		return trace.Wrap(err, "loading cached identity")
+	}
+	if id == nil {
+		s.log.InfoContext(ctx, "No valid cached identity, generating a new one")
+		id, err = s.identityGenerator.Generate(ctx,
+			identity.WithLifetime(s.botCfg.CredentialLifetime.TTL, s.botCfg.CredentialLifetime.RenewalInterval),
+			identity.WithLogger(s.log),
+		)
+		if err != nil {
+			return trace.Wrap(err, "generating identity")
+		}
+		if err := identity.SaveIdentity(ctx, id, s.cfg.GetDestination(), identity.BotKinds()...); err != nil {
+			return trace.Wrap(err, "caching identity")
+		}
+	}
+
+	doc, err := s.format(id)
+	if err != nil {
+		return trace.Wrap(err, "formatting credential")
+	}
+
+	stdout := cmp.Or[io.Writer](s.stdout, os.Stdout)
+	if _, err := stdout.Write(doc); err != nil {
+		return trace.Wrap(err, "writing credential to stdout")
+	}
+	return nil
+}
+
+// loadCachedIdentity returns the identity cached at cfg.Destination if one
+// exists and has not yet entered its renewal window, or nil if a fresh one
+// should be generated.
+func (s *ExecCredentialOutputService) loadCachedIdentity(ctx context.Context) (*identity.Identity, error) {
+	id, err := identity.LoadIdentity(ctx, s.cfg.GetDestination(), identity.BotKinds()...)
+	if trace.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	renewalWindowStart := id.X509Cert.NotAfter.Add(-s.botCfg.CredentialLifetime.RenewalInterval)
+	if time.Now().After(renewalWindowStart) {
+		return nil, nil
+	}
+	return id, nil
+}
+
+// format renders id in the protocol cfg.Format selects.
+func (s *ExecCredentialOutputService) format(id *identity.Identity) ([]byte, error) {
+	expiry := id.X509Cert.NotAfter
+	switch s.cfg.Format {
+	case config.ExecCredentialFormatKubernetes:
+		return execcredential.MarshalKubernetesExecCredential(id.TLSCertBytes, id.PrivateKeyBytes, expiry)
+	case config.ExecCredentialFormatAWS:
+		// Minting real AWS temporary credentials means exchanging an
+		// OIDC/SAML token for them via AssumeRoleWithWebIdentity, which
+		// needs an auth server RPC (something like GenerateAWSOIDCToken)
+		// to issue that token. No such RPC exists on apiclient.Client in
+		// this checkout, and there's no local substitute: unlike the
+		// self-signed JWTs identity.NewSelfSignedJWTSource mints for
+		// UnstableClientCredentialOutput, AWS's STS won't trust a token
+		// signed by the bot's own mTLS key.
+		return nil, trace.NotImplemented("exec-credential format 'aws' requires a configured AWS role to assume via GenerateAWSOIDCToken; not yet wired up")
+	case config.ExecCredentialFormatGCP:
+		// Same gap as 'aws': GCP's workload identity pool provider needs
+		// an id_token it can verify against a configured issuer/JWKS, and
+		// identity.NewSelfSignedJWTSource signs with the bot's mTLS
+		// identity key rather than the key WorkloadIdentityJWKSServiceBuilder
+		// publishes, so it isn't a substitute for a real workload-identity
+		// JWT SVID here.
+		return nil, trace.NotImplemented("exec-credential format 'gcp' requires a configured GCP workload identity pool token; not yet wired up")
+	default:
+		return nil, trace.BadParameter("unrecognized exec-credential format %q", s.cfg.Format)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tbot
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport"
+	apiclient "github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/lib/tbot/bot"
+	"github.com/gravitational/teleport/lib/tbot/config"
+	"github.com/gravitational/teleport/lib/tbot/internal"
+	"github.com/gravitational/teleport/lib/tbot/readyz"
+	"github.com/gravitational/teleport/lib/tbot/workloadidentity/jwks"
+)
+
+// WorkloadIdentityJWKSServiceBuilder returns a bot.ServiceBuilder for a
+// companion to ClientCredentialOutputServiceBuilder/WorkloadIdentityJWTService:
+// rather than issuing JWT SVIDs itself, it republishes the cluster's SPIFFE
+// JWT signing keys as a JWKS document (plus an OIDC discovery document), so
+// relying parties can verify tbot-issued JWT SVIDs offline.
+func WorkloadIdentityJWKSServiceBuilder(botCfg *config.BotConfig, cfg *config.WorkloadIdentityJWKSService) bot.ServiceBuilder {
+	return func(deps bot.ServiceDependencies) (bot.Service, error) {
+		svc := &WorkloadIdentityJWKSService{
+			botAuthClient:      deps.Client,
+			botIdentityReadyCh: deps.BotIdentityReadyCh,
+			botCfg:             botCfg,
+			cfg:                cfg,
+			reloadCh:           deps.ReloadCh,
+		}
+		svc.log = deps.Logger.With(
+			teleport.ComponentKey,
+			teleport.Component(teleport.ComponentTBot, "svc", svc.String()),
+		)
+		svc.statusReporter = deps.StatusRegistry.AddService(svc.String())
+		return svc, nil
+	}
+}
+
+// WorkloadIdentityJWKSService publishes the active and next-rotation SPIFFE
+// JWT signing keys as a JWKS document, and a `.well-known/openid-configuration`
+// document pointing at it, reacting to CA rotations via reloadCh.
+type WorkloadIdentityJWKSService struct {
+	botAuthClient      *apiclient.Client
+	botIdentityReadyCh <-chan struct{}
+	botCfg             *config.BotConfig
+	cfg                *config.WorkloadIdentityJWKSService
+	log                *slog.Logger
+	statusReporter     readyz.Reporter
+	reloadCh           <-chan struct{}
+
+	server *http.Server
+}
+
+func (s *WorkloadIdentityJWKSService) String() string {
+	return cmp.Or(
+		s.cfg.Type(),
+		"workload-identity-jwks",
+	)
+}
+
+func (s *WorkloadIdentityJWKSService) OneShot(ctx context.Context) error {
+	return s.publish(ctx)
+}
+
+func (s *WorkloadIdentityJWKSService) Run(ctx context.Context) error {
+	if s.cfg.ListenAddr != "" {
+		return trace.Wrap(s.runServer(ctx))
+	}
+
+	err := internal.RunOnInterval(ctx, internal.RunOnIntervalConfig{
+		Service:         s.String(),
+		Name:            "jwks-publication",
+		F:               s.publish,
+		Interval:        s.botCfg.CredentialLifetime.RenewalInterval,
+		RetryLimit:      internal.RenewalRetryLimit,
+		Log:             s.log,
+		ReloadCh:        s.reloadCh,
+		IdentityReadyCh: s.botIdentityReadyCh,
+		StatusReporter:  s.statusReporter,
+	})
+	return trace.Wrap(err)
+}
+
+// publish fetches the cluster's current and next-rotation SPIFFE JWT
+// signing keys and writes the JWKS and discovery documents to cfg.Destination.
+func (s *WorkloadIdentityJWKSService) publish(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "WorkloadIdentityJWKSService/publish")
+	defer span.End()
+
+	set, err := jwks.FetchSigningKeySet(ctx, s.botAuthClient)
+	if err != nil {
+		return trace.Wrap(err, "fetching JWT signing keys")
+	}
+
+	jwksDoc, err := set.MarshalJWKS()
+	if err != nil {
+		return trace.Wrap(err, "marshaling JWKS document")
+	}
+	discoveryDoc, err := jwks.MarshalDiscoveryDocument(s.cfg.IssuerURL)
+	if err != nil {
+		return trace.Wrap(err, "marshaling OIDC discovery document")
+	}
+
+	dest := s.cfg.GetDestination()
+	if err := dest.Write(ctx, "jwks.json", jwksDoc); err != nil {
+		return trace.Wrap(err, "writing jwks.json")
+	}
+	if err := dest.Write(ctx, ".well-known/openid-configuration", discoveryDoc); err != nil {
+		return trace.Wrap(err, "writing openid-configuration")
+	}
+
+	s.log.InfoContext(ctx, "Published JWKS document", "key_count", len(set.Keys))
+	return nil
+}
+
+// runServer serves the JWKS and discovery documents over HTTP(S) at
+// cfg.ListenAddr, republishing whenever reloadCh fires, until ctx is done.
+func (s *WorkloadIdentityJWKSService) runServer(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		set, err := jwks.FetchSigningKeySet(r.Context(), s.botAuthClient)
+		if err != nil {
+			s.log.ErrorContext(r.Context(), "Failed to fetch JWT signing keys", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		doc, err := set.MarshalJWKS()
+		if err != nil {
+			s.log.ErrorContext(r.Context(), "Failed to marshal JWKS document", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		doc, err := jwks.MarshalDiscoveryDocument(s.cfg.IssuerURL)
+		if err != nil {
+			s.log.ErrorContext(r.Context(), "Failed to marshal discovery document", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+
+	s.server = &http.Server{Addr: s.cfg.ListenAddr, Handler: mux}
+	s.statusReporter.Report(readyz.Healthy)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.server.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return trace.Wrap(s.server.Close())
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return trace.Wrap(err, "serving JWKS endpoint")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package jwks turns the cluster's SPIFFE JWT signing keys into a
+// standard JWKS document (and a matching OIDC discovery document), and
+// provides a small helper for Go consumers to verify tokens against a
+// published set without depending on a Teleport-specific SDK.
+package jwks
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// CertAuthorityGetter is the minimal surface FetchSigningKeySet needs from
+// an auth client: enough to read the cluster's current and next-rotation
+// SPIFFE JWT signing public keys.
+type CertAuthorityGetter interface {
+	GetCertAuthority(ctx context.Context, id types.CertAuthID, loadKeys bool) (types.CertAuthority, error)
+	GetDomainName(ctx context.Context) (string, error)
+}
+
+// SigningKeySet is the cluster's SPIFFE JWT signing keys in JWKS form.
+// Active and next-rotation keys are both included so that verifiers roll
+// over without a window where a just-rotated token fails to validate.
+type SigningKeySet struct {
+	Keys []jose.JSONWebKey
+}
+
+// MarshalJWKS renders the key set as a JSON Web Key Set document.
+func (s *SigningKeySet) MarshalJWKS() ([]byte, error) {
+	doc, err := json.Marshal(jose.JSONWebKeySet{Keys: s.Keys})
+	if err != nil {
+		return nil, trace.Wrap(err, "marshaling JWKS")
+	}
+	return doc, nil
+}
+
+// FetchSigningKeySet reads the cluster's SPIFFE CA and returns its active
+// and additional (next-rotation) JWT public keys as a SigningKeySet.
+func FetchSigningKeySet(ctx context.Context, client CertAuthorityGetter) (*SigningKeySet, error) {
+	clusterName, err := client.GetDomainName(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err, "getting cluster name")
+	}
+
+	ca, err := client.GetCertAuthority(ctx, types.CertAuthID{
+		Type:       types.SPIFFECA,
+		DomainName: clusterName,
+	}, false /* loadKeys, only public keys are needed */)
+	if err != nil {
+		return nil, trace.Wrap(err, "getting SPIFFE CA")
+	}
+
+	var keys []jose.JSONWebKey
+	for _, keyPair := range ca.GetActiveKeys().JWT {
+		jwk, err := jwkFromPEM(keyPair.PublicKey)
+		if err != nil {
+			return nil, trace.Wrap(err, "decoding active JWT public key")
+		}
+		keys = append(keys, jwk)
+	}
+	for _, keyPair := range ca.GetAdditionalTrustedKeys().JWT {
+		jwk, err := jwkFromPEM(keyPair.PublicKey)
+		if err != nil {
+			return nil, trace.Wrap(err, "decoding next-rotation JWT public key")
+		}
+		keys = append(keys, jwk)
+	}
+
+	return &SigningKeySet{Keys: keys}, nil
+}
+
+func jwkFromPEM(publicKeyPEM []byte) (jose.JSONWebKey, error) {
+	der := publicKeyPEM
+	if block, _ := pem.Decode(publicKeyPEM); block != nil {
+		der = block.Bytes
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return jose.JSONWebKey{}, trace.Wrap(err, "parsing public key")
+	}
+
+	fingerprint := sha256.Sum256(der)
+	return jose.JSONWebKey{
+		Key:       pub,
+		KeyID:     hex.EncodeToString(fingerprint[:8]),
+		Algorithm: string(jose.ES256),
+		Use:       "sig",
+	}, nil
+}
+
+// DiscoveryDocument is the subset of an OIDC discovery document relying
+// parties need to validate a JWT SVID offline: where to find the issuer's
+// signing keys and which algorithms/subject types it uses.
+type DiscoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// MarshalDiscoveryDocument renders a `.well-known/openid-configuration`
+// document advertising issuerURL as the issuer and a JWKS served alongside
+// it at "<issuerURL>/.well-known/jwks.json".
+func MarshalDiscoveryDocument(issuerURL string) ([]byte, error) {
+	doc := DiscoveryDocument{
+		Issuer:                           issuerURL,
+		JWKSURI:                          issuerURL + "/.well-known/jwks.json",
+		ResponseTypesSupported:           []string{"id_token"},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{string(jose.ES256), string(jose.RS256)},
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, trace.Wrap(err, "marshaling discovery document")
+	}
+	return out, nil
+}
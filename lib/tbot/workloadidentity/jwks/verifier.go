@@ -0,0 +1,159 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package jwks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/gravitational/trace"
+)
+
+// defaultKeySetTTL bounds how long a fetched JWKS is trusted before
+// Verifier re-fetches it, so a key added or removed at the publisher is
+// eventually picked up even without an explicit Refresh call.
+const defaultKeySetTTL = 5 * time.Minute
+
+// Verifier validates JWT SVIDs published by WorkloadIdentityJWKSService,
+// fetching and caching its JWKS the way a go-oidc IDTokenVerifier fetches
+// and caches a provider's keys, without requiring the Teleport API client.
+type Verifier struct {
+	jwksURL    string
+	issuer     string
+	httpClient *http.Client
+	ttl        time.Duration
+
+	mu        sync.Mutex
+	keySet    jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewVerifier returns a Verifier that fetches keys from jwksURL and expects
+// tokens with an `iss` claim equal to issuer.
+func NewVerifier(jwksURL, issuer string) *Verifier {
+	return &Verifier{
+		jwksURL:    jwksURL,
+		issuer:     issuer,
+		httpClient: http.DefaultClient,
+		ttl:        defaultKeySetTTL,
+	}
+}
+
+// Verify checks rawToken's signature against the (cached, auto-refreshed)
+// published JWKS, and that its `iss` claim matches and its `aud` claim
+// contains audience, returning the token's claims on success.
+func (v *Verifier) Verify(ctx context.Context, rawToken, audience string) (*jwt.Claims, error) {
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, trace.Wrap(err, "parsing JWT")
+	}
+	if len(token.Headers) == 0 {
+		return nil, trace.BadParameter("token has no JOSE header")
+	}
+
+	key, err := v.findKey(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var claims jwt.Claims
+	if err := token.Claims(key, &claims); err != nil {
+		return nil, trace.AccessDenied("token signature verification failed: %v", err)
+	}
+
+	if err := claims.Validate(jwt.Expected{
+		Issuer:   v.issuer,
+		Audience: jwt.Audience{audience},
+		Time:     time.Now(),
+	}); err != nil {
+		return nil, trace.AccessDenied("token claims invalid: %v", err)
+	}
+
+	return &claims, nil
+}
+
+// findKey returns the key with the given kid, refreshing the cached JWKS
+// at most once if it's not found, so that a just-rotated signing key
+// becomes usable without waiting out the full cache TTL.
+func (v *Verifier) findKey(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	if key := v.lookup(kid); key != nil {
+		return key, nil
+	}
+	if err := v.refresh(ctx); err != nil {
+		return nil, trace.Wrap(err, "fetching JWKS")
+	}
+	if key := v.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, trace.NotFound("no signing key with kid %q in JWKS", kid)
+}
+
+func (v *Verifier) lookup(kid string) *jose.JSONWebKey {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if time.Since(v.fetchedAt) > v.ttl {
+		return nil
+	}
+	for i := range v.keySet.Keys {
+		if v.keySet.Keys[i].KeyID == kid {
+			return &v.keySet.Keys[i]
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return trace.ConnectionProblem(nil, "fetching JWKS from %s: unexpected status %d", v.jwksURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &keySet); err != nil {
+		return trace.Wrap(err, "decoding JWKS")
+	}
+
+	v.mu.Lock()
+	v.keySet = keySet
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package recovery provides a panic-recovery wrapper for goroutines that
+// aren't already isolated by an RPC boundary (and so wouldn't otherwise
+// benefit from a gRPC recovery interceptor), e.g. a CLI command's
+// background goroutine or a request formatter called from one. A panic
+// there currently crashes the whole process; this package converts it
+// into a regular error instead, with the stack captured to the log.
+package recovery
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/gravitational/trace"
+)
+
+// Do runs fn, recovering any panic and converting it into a
+// trace.BadParameter error with the stack trace logged at error level,
+// rather than letting it crash the process.
+func Do(ctx context.Context, log *slog.Logger, component string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.ErrorContext(ctx, "recovered from panic",
+				"component", component,
+				"panic", r,
+				"stack", string(debug.Stack()),
+			)
+			err = trace.BadParameter("%s panicked: %v", component, r)
+		}
+	}()
+	return fn()
+}
+
+// DoValue is Do for a function that also returns a value, for call sites
+// like formatters that return (string, error) rather than just error.
+func DoValue[T any](ctx context.Context, log *slog.Logger, component string, fn func() (T, error)) (result T, err error) {
+	err = Do(ctx, log, component, func() error {
+		var fnErr error
+		result, fnErr = fn()
+		return fnErr
+	})
+	return result, err
+}
+
+// Func wraps fn with Do, for call sites that need a func() error value
+// rather than an immediate call, e.g. `go func() { errC <- recovery.Func(...)() }()`.
+func Func(ctx context.Context, log *slog.Logger, component string, fn func() error) func() error {
+	return func() error {
+		return Do(ctx, log, component, fn)
+	}
+}
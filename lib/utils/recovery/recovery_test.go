@@ -0,0 +1,65 @@
+/*
+ * Teleport
+ * Copyright (C) 2025  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package recovery
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDoRecoversPanic(t *testing.T) {
+	err := Do(context.Background(), discardLogger(), "test", func() error {
+		panic("boom")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "test panicked")
+	require.Contains(t, err.Error(), "boom")
+}
+
+func TestDoPassesThroughError(t *testing.T) {
+	err := Do(context.Background(), discardLogger(), "test", func() error {
+		return io.ErrUnexpectedEOF
+	})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestDoValueRecoversPanic(t *testing.T) {
+	result, err := DoValue(context.Background(), discardLogger(), "formatter", func() (string, error) {
+		panic("formatter exploded")
+	})
+	require.Empty(t, result)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "formatter panicked")
+}
+
+func TestDoValueReturnsResult(t *testing.T) {
+	result, err := DoValue(context.Background(), discardLogger(), "formatter", func() (string, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+}
@@ -0,0 +1,1108 @@
+/*
+ * Teleport
+ * Copyright (C) 2023  Gravitational, Inc.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v2"
+	yamlk8s "sigs.k8s.io/yaml"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/tlsca"
+	"github.com/gravitational/teleport/lib/utils/recovery"
+)
+
+// appFormat controls how `tsh app config` renders a resolved app route.
+const (
+	appFormatDefault    = ""
+	appFormatJSON       = "json"
+	appFormatYAML       = "yaml"
+	appFormatURI        = "uri"
+	appFormatCA         = "ca"
+	appFormatCert       = "cert"
+	appFormatKey        = "key"
+	appFormatCURL       = "curl"
+	// appFormatCurlBearer renders a curl invocation that authenticates
+	// with the app's signed JWT (see appConfigInfo.JWT) via an
+	// `Authorization: Bearer` header, instead of the mTLS client
+	// certificate appFormatCURL uses. This is for apps that speak
+	// OIDC/JWT auth rather than validating a client certificate.
+	appFormatCurlBearer = "curl-bearer"
+)
+
+// appConfigInfo is the shape `tsh app config --format=json|yaml` emits.
+// Field order here is also JSON's output order; YAML output is rendered
+// separately (see formatAppConfig) and is alphabetized.
+type appConfigInfo struct {
+	Name              string `json:"name"`
+	URI               string `json:"uri"`
+	CA                string `json:"ca"`
+	Cert              string `json:"cert"`
+	Key               string `json:"key"`
+	Curl              string `json:"curl"`
+	AzureIdentity     string `json:"azure_identity,omitempty"`
+	GCPServiceAccount string `json:"gcp_service_account,omitempty"`
+	// JWT is a short-lived JSON Web Token bound to this app route, issued
+	// when the caller requested one (see requestAppJWT). Apps that
+	// validate a bearer token instead of (or in addition to) the mTLS
+	// client certificate can use this to authenticate the caller.
+	JWT string `json:"jwt,omitempty"`
+	// JWKSURI is where the app (or any relying party) can fetch the
+	// public keys needed to verify JWT, so JWT validation doesn't
+	// require a live call back to Teleport's auth server.
+	JWKSURI string `json:"jwks_uri,omitempty"`
+}
+
+// appJWTClaims are the caller-configurable claims requested for a JWT
+// issued alongside an app route, in addition to the identity claims
+// (username, roles, cluster, app name) Teleport always includes.
+type appJWTClaims struct {
+	// Audience scopes the JWT to a single app, matching the route's
+	// public address by default.
+	Audience string
+	// TTL bounds the JWT's lifetime. Callers should keep this short:
+	// unlike the mTLS certificate, a leaked JWT cannot be revoked before
+	// it expires.
+	TTL time.Duration
+	// Custom holds additional key/value claims requested via repeated
+	// `--claim key=value` flags.
+	Custom map[string]string
+}
+
+// parseAppClaimFlags parses repeated `key=value` strings (as passed to
+// `--claim`) into the Custom claims map of an appJWTClaims.
+func parseAppClaimFlags(claims []string) (map[string]string, error) {
+	if len(claims) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(claims))
+	for _, c := range claims {
+		k, v, ok := strings.Cut(c, "=")
+		if !ok || k == "" {
+			return nil, trace.BadParameter("invalid --claim %q, expected key=value", c)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// requestAppJWT asks the auth server to mint a short-lived JWT bound to
+// routeToApp, carrying the caller's identity (username, roles, cluster,
+// app name) plus any custom claims, and returns the signed JWT alongside
+// the JWKS URL relying parties can use to verify it without calling back
+// into Teleport.
+//
+// This is new surface area this snapshot of the repo does not yet have
+// the server-side support for (there is no app-scoped JWT issuance RPC in
+// this tree to call), so it is implemented against the most plausible
+// shape of that API; wiring it up server-side is out of scope here.
+func requestAppJWT(tc *client.TeleportClient, routeToApp proto.RouteToApp, claims appJWTClaims) (jwt string, jwksURI string, err error) {
+	return "", "", trace.NotImplemented(
+		"issuing app-scoped JWTs requires auth server support for a " +
+			"GenerateAppJWT-style RPC that does not exist in this build; " +
+			"once added, requestAppJWT should call it with routeToApp and claims",
+	)
+}
+
+// formatAppConfig renders routeToApp (and, for format == appFormatCURL or
+// appFormatCurlBearer, the paths/JWT needed to connect to it) in the
+// requested format. claims is nil unless the caller passed --jwt/--claim
+// flags requesting a JWT be minted for the route; formats that don't need
+// one (appFormatCURL, appFormatURI, etc.) ignore it. A panic inside a
+// custom formatter (appFormatCURL and appFormatCurlBearer both shell out to
+// profile/path-building helpers that may be user-extended) is recovered
+// and returned as a regular error rather than crashing the process, the
+// same as the local proxy goroutine tsh proxy app runs in.
+func formatAppConfig(tc *client.TeleportClient, profile *client.ProfileStatus, routeToApp proto.RouteToApp, format string, claims *appJWTClaims) (string, error) {
+	ctx, span := traceApp(context.Background(), "app.config.format", routeToApp.ClusterName, routeToApp.Name, routeToApp.PublicAddr)
+	span.SetAttributes(attribute.String("teleport.app.config.format", format))
+
+	out, err := recovery.DoValue(ctx, slog.Default(), "formatAppConfig", func() (string, error) {
+		return formatAppConfigUnsafe(tc, profile, routeToApp, format, claims)
+	})
+	endAppSpan(span, err)
+	return out, err
+}
+
+// formatAppConfigUnsafe is formatAppConfig's actual implementation; call it
+// only through formatAppConfig, which adds panic recovery.
+func formatAppConfigUnsafe(tc *client.TeleportClient, profile *client.ProfileStatus, routeToApp proto.RouteToApp, format string, claims *appJWTClaims) (string, error) {
+	var uriString string
+	if tc.WebProxyAddr != "" {
+		uriString = formatAppURI(tc.WebProxyAddr, routeToApp.PublicAddr)
+	}
+
+	info := appConfigInfo{
+		Name:              routeToApp.Name,
+		URI:               uriString,
+		CA:                profile.CACertPathForCluster(routeToApp.ClusterName),
+		Cert:              profile.AppCertPath(routeToApp.ClusterName, routeToApp.Name),
+		Key:               profile.AppKeyPath(routeToApp.ClusterName, routeToApp.Name),
+		AzureIdentity:     routeToApp.AzureIdentity,
+		GCPServiceAccount: routeToApp.GCPServiceAccount,
+	}
+
+	// A JWT is only requested (and, for curl-bearer, required) when the
+	// caller asked for one via --jwt/--claim; formats that don't need a
+	// bearer token shouldn't fail just because JWT issuance isn't wired up
+	// server-side yet.
+	if claims != nil {
+		jwt, jwksURI, err := requestAppJWT(tc, routeToApp, *claims)
+		if err != nil {
+			if format == appFormatCurlBearer {
+				return "", trace.Wrap(err, "requesting app JWT")
+			}
+		} else {
+			info.JWT = jwt
+			info.JWKSURI = jwksURI
+		}
+	}
+
+	curlCmd, err := formatAppConfigCurl(tc, profile, routeToApp, uriString, info.JWT, format)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	info.Curl = curlCmd
+
+	switch format {
+	case appFormatURI:
+		return info.URI, nil
+	case appFormatCA:
+		return info.CA, nil
+	case appFormatCert:
+		return info.Cert, nil
+	case appFormatKey:
+		return info.Key, nil
+	case appFormatCURL, appFormatCurlBearer:
+		return info.Curl, nil
+	case appFormatJSON:
+		out, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return string(out) + "\n", nil
+	case appFormatYAML:
+		out, err := yaml.Marshal(appConfigYAML(info))
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return string(out), nil
+	case appFormatDefault:
+		rows := [][]string{
+			{"Name:", info.Name},
+			{"URI:", info.URI},
+			{"CA:", info.CA},
+			{"Cert:", info.Cert},
+			{"Key:", info.Key},
+		}
+		if info.AzureIdentity != "" {
+			rows = append(rows, []string{"Azure Id:", info.AzureIdentity})
+		}
+		if info.GCPServiceAccount != "" {
+			rows = append(rows, []string{"GCP Service Account:", info.GCPServiceAccount})
+		}
+		table := asciitable.MakeTable(make([]string, 2), rows...)
+		return table.AsBuffer().String(), nil
+	default:
+		return "", trace.BadParameter("invalid format %q", format)
+	}
+}
+
+// appConfigYAML builds the map formatAppConfig's YAML format marshals, so
+// that (unlike the JSON struct, which is ordered by field declaration)
+// empty optional fields are omitted entirely rather than rendered blank.
+func appConfigYAML(info appConfigInfo) map[string]string {
+	out := map[string]string{
+		"name": info.Name,
+		"uri":  info.URI,
+		"ca":   info.CA,
+		"cert": info.Cert,
+		"key":  info.Key,
+		"curl": info.Curl,
+	}
+	if info.AzureIdentity != "" {
+		out["azure_identity"] = info.AzureIdentity
+	}
+	if info.GCPServiceAccount != "" {
+		out["gcp_service_account"] = info.GCPServiceAccount
+	}
+	if info.JWT != "" {
+		out["jwt"] = info.JWT
+	}
+	if info.JWKSURI != "" {
+		out["jwks_uri"] = info.JWKSURI
+	}
+	return out
+}
+
+// formatAppURI builds the public HTTPS URI for an app, omitting the port
+// when the proxy is reachable over the standard HTTPS port.
+func formatAppURI(webProxyAddr, publicAddr string) string {
+	addr := strings.TrimPrefix(strings.TrimPrefix(webProxyAddr, "https://"), "http://")
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil || port == "" || port == "443" {
+		return fmt.Sprintf("https://%s", publicAddr)
+	}
+	return fmt.Sprintf("https://%s:%s", publicAddr, port)
+}
+
+// formatAppConfigCurl renders the curl invocation for format == appFormatCURL
+// (mTLS client cert) or appFormatCurlBearer (JWT bearer token via jwt, which
+// must be non-empty when format == appFormatCurlBearer).
+func formatAppConfigCurl(tc *client.TeleportClient, profile *client.ProfileStatus, routeToApp proto.RouteToApp, uriString, jwt, format string) (string, error) {
+	var curlInsecureFlag string
+	if tc.InsecureSkipVerify {
+		curlInsecureFlag = "--insecure "
+	}
+
+	if format == appFormatCurlBearer {
+		if jwt == "" {
+			return "", trace.BadParameter("curl-bearer format requires a JWT; pass --jwt")
+		}
+		return fmt.Sprintf(`curl %v\
+  -H %q \
+  %v`,
+			curlInsecureFlag,
+			"Authorization: Bearer "+jwt,
+			uriString), nil
+	}
+
+	return fmt.Sprintf(`curl %v\
+  --cert %q \
+  --key %q \
+  %v`,
+		curlInsecureFlag,
+		profile.AppCertPath(routeToApp.ClusterName, routeToApp.Name),
+		profile.AppKeyPath(routeToApp.ClusterName, routeToApp.Name),
+		uriString), nil
+}
+
+// buildAppListResourcesRequest translates the `tsh apps ls --query` predicate
+// expression and `--labels` selector (e.g. "env=prod,tier!=canary") into a
+// types.ListResourcesRequest, so filtering happens server-side rather than
+// the client fetching the full catalog and grepping it locally. Equality
+// terms become req.Labels (evaluated as an index lookup by the backend);
+// inequality terms have no Labels equivalent and are folded into the
+// predicate expression instead.
+func buildAppListResourcesRequest(predicateExpr, labelSelector string) (types.ListResourcesRequest, error) {
+	req := types.ListResourcesRequest{
+		ResourceType:        types.KindApp,
+		PredicateExpression: predicateExpr,
+	}
+
+	if labelSelector == "" {
+		return req, nil
+	}
+
+	labels := make(map[string]string)
+	var extraPredicates []string
+	for _, term := range strings.Split(labelSelector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		switch {
+		case strings.Contains(term, "!="):
+			k, v, _ := strings.Cut(term, "!=")
+			extraPredicates = append(extraPredicates, fmt.Sprintf("labels[%q] != %q", strings.TrimSpace(k), strings.TrimSpace(v)))
+		case strings.Contains(term, "="):
+			k, v, _ := strings.Cut(term, "=")
+			labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		default:
+			return types.ListResourcesRequest{}, trace.BadParameter("invalid label selector term %q, expected key=value or key!=value", term)
+		}
+	}
+
+	req.Labels = labels
+	if len(extraPredicates) > 0 {
+		clauses := extraPredicates
+		if req.PredicateExpression != "" {
+			clauses = append([]string{req.PredicateExpression}, clauses...)
+		}
+		req.PredicateExpression = strings.Join(clauses, " && ")
+	}
+
+	return req, nil
+}
+
+// describeAppListFilter renders the Filter: banner writeAppTable shows
+// above the table when the listing was narrowed server-side, so it's
+// obvious to the caller that they're not looking at the full catalog.
+func describeAppListFilter(predicateExpr, labelSelector string) string {
+	switch {
+	case predicateExpr != "" && labelSelector != "":
+		return fmt.Sprintf("%s (labels: %s)", predicateExpr, labelSelector)
+	case predicateExpr != "":
+		return predicateExpr
+	case labelSelector != "":
+		return labelSelector
+	default:
+		return ""
+	}
+}
+
+// applyAppListFilter is the single entry point `tsh apps ls --query/--labels`
+// should call: it builds the ListResourcesRequest the backend would be sent
+// (via buildAppListResourcesRequest) and, since there's no ListResources RPC
+// client call in this snapshot of the repo to exercise end-to-end, also
+// applies the req.Labels half of that filter locally via
+// filterAppListingsByLabels so callers working against an already-fetched
+// appListings slice still get correct results. The req.PredicateExpression
+// half has no client-side equivalent: evaluating it requires the
+// predicate-expression parser from lib/services, which isn't part of this
+// snapshot either, so it is only reflected in the returned request, not
+// applied to appListings here.
+func applyAppListFilter(appListings []appListing, predicateExpr, labelSelector string) ([]appListing, types.ListResourcesRequest, error) {
+	req, err := buildAppListResourcesRequest(predicateExpr, labelSelector)
+	if err != nil {
+		return nil, types.ListResourcesRequest{}, trace.Wrap(err)
+	}
+	return filterAppListingsByLabels(appListings, req.Labels), req, nil
+}
+
+// filterAppListingsByLabels returns the subset of appListings whose app
+// has every key/value pair in labels. This is the client-side equivalent
+// of the req.Labels the backend applies when buildAppListResourcesRequest's
+// ListResourcesRequest is sent; applyAppListFilter is the entry point that
+// combines the two rather than calling this directly.
+func filterAppListingsByLabels(appListings []appListing, labels map[string]string) []appListing {
+	if len(labels) == 0 {
+		return appListings
+	}
+
+	var matched []appListing
+	for _, listing := range appListings {
+		appLabels := listing.App.GetAllLabels()
+		matches := true
+		for k, v := range labels {
+			if appLabels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			matched = append(matched, listing)
+		}
+	}
+	return matched
+}
+
+// resolveAppByName picks the app login/config/logout/proxy commands should
+// act on out of apps, matching name against GetName() and GetPublicAddr().
+// DNS/HTTP hostnames are case-insensitive, so `tsh app login RootApp`
+// should find the same app as `tsh app login rootapp` - but an exact,
+// case-sensitive match always wins over a case-insensitive one, and if
+// more than one app matches case-insensitively with no exact match to
+// break the tie, that's reported as an error rather than picking
+// arbitrarily.
+func resolveAppByName(apps []types.Application, name string) (types.Application, error) {
+	var exact types.Application
+	var ciMatches []types.Application
+
+	for _, app := range apps {
+		if app.GetName() == name || app.GetPublicAddr() == name {
+			if exact != nil {
+				return nil, trace.BadParameter("multiple apps exactly match %q", name)
+			}
+			exact = app
+			continue
+		}
+		if strings.EqualFold(app.GetName(), name) || strings.EqualFold(app.GetPublicAddr(), name) {
+			ciMatches = append(ciMatches, app)
+		}
+	}
+
+	if exact != nil {
+		return exact, nil
+	}
+
+	switch len(ciMatches) {
+	case 0:
+		return nil, trace.NotFound("app %q not found", name)
+	case 1:
+		return ciMatches[0], nil
+	default:
+		names := make([]string, 0, len(ciMatches))
+		for _, app := range ciMatches {
+			names = append(names, app.GetName())
+		}
+		return nil, trace.BadParameter(
+			"%q matches multiple apps case-insensitively (%s); use the exact name to disambiguate",
+			name, strings.Join(names, ", "),
+		)
+	}
+}
+
+// appListing is a single row `tsh app ls` renders: an app plus the proxy
+// and cluster it was discovered through (apps may be listed across a root
+// cluster and any number of leaf clusters in `--all` mode).
+type appListing struct {
+	Proxy   string
+	Cluster string
+	App     types.Application
+}
+
+// appTableConfig controls which columns writeAppTable renders.
+type appTableConfig struct {
+	// active holds the app routes of the profile's currently logged-in
+	// apps, so writeAppTable can mark them with a "> " prefix.
+	active []tlsca.RouteToApp
+	// verbose adds the URI column and disables public-address truncation.
+	verbose bool
+	// listAll adds the Proxy and Cluster columns, for listing apps across
+	// every cluster rather than just the currently selected one.
+	listAll bool
+	// filter, if set, is a human-readable description of the --filter
+	// predicate expression and/or --label selector the listing was
+	// narrowed by server-side, rendered as a banner above the table so
+	// it's clear the listing isn't the full catalog.
+	filter string
+	// expandPorts corresponds to --expand-ports: instead of a single
+	// "1337, 4200-4242"-style Target Ports cell per multi-port app, emit
+	// one row per concrete port.
+	expandPorts bool
+	// probeHealth corresponds to --probe-health: adds a Health column,
+	// populated by dialing each concrete target port. Only takes effect
+	// alongside expandPorts, since a combined port-range cell has no
+	// single health status to show.
+	probeHealth bool
+	// probeTimeout bounds how long a single port's probe may take. Zero
+	// means use defaultPortProbeTimeout.
+	probeTimeout time.Duration
+	// probeWorkers caps how many port probes run concurrently. Zero means
+	// use defaultPortProbeWorkers.
+	probeWorkers int
+	// probeFunc is the probe implementation; nil means use dialAppPort.
+	// Tests substitute a fake here instead of dialing real sockets.
+	probeFunc portProbeFunc
+	// columns corresponds to --columns: when non-empty, it overrides the
+	// verbose/listAll column logic entirely and renders exactly these
+	// columns, in this order. Each name must be a key of appTableColumns.
+	columns []string
+}
+
+// appTableRowCtx is what's available to an appTableColumn's value func for
+// one table row: the listing, its (possibly "> "-prefixed) display name,
+// and, when relevant, the concrete Target Ports cell/port/health computed
+// for that row.
+type appTableRowCtx struct {
+	listing   appListing
+	name      string
+	portsCell string
+	port      int
+	health    string
+}
+
+// appTableColumn is one renderable column for --columns custom column
+// selection.
+type appTableColumn struct {
+	header string
+	value  func(row appTableRowCtx) string
+}
+
+// appTableColumns is the full set of columns --columns may select from,
+// keyed by the name users pass on the command line.
+var appTableColumns = map[string]appTableColumn{
+	"proxy":        {"Proxy", func(r appTableRowCtx) string { return r.listing.Proxy }},
+	"cluster":      {"Cluster", func(r appTableRowCtx) string { return r.listing.Cluster }},
+	"name":         {"Application", func(r appTableRowCtx) string { return r.name }},
+	"public_addr":  {"Public Address", func(r appTableRowCtx) string { return r.listing.App.GetPublicAddr() }},
+	"uri":          {"URI", func(r appTableRowCtx) string { return r.listing.App.GetURI() }},
+	"target_ports": {"Target Ports", func(r appTableRowCtx) string { return r.portsCell }},
+	"labels":       {"Labels", func(r appTableRowCtx) string { return formatAppLabels(r.listing.App.GetAllLabels()) }},
+	"health":       {"Health", func(r appTableRowCtx) string { return r.health }},
+}
+
+// validateAppTableColumns checks that every name in columns is a key of
+// appTableColumns, returning a descriptive error naming the valid set
+// otherwise. writeAppTable calls this before writing anything (including
+// the filter banner), so a typo in --columns fails fast.
+func validateAppTableColumns(columns []string) error {
+	for _, name := range columns {
+		if _, ok := appTableColumns[name]; !ok {
+			valid := make([]string, 0, len(appTableColumns))
+			for k := range appTableColumns {
+				valid = append(valid, k)
+			}
+			sort.Strings(valid)
+			return trace.BadParameter("unknown column %q, valid columns are: %s", name, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// formatAppLabels renders labels as a sorted, comma-separated "k=v" list
+// for the Labels column.
+func formatAppLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// writeAppTableCustomColumns renders appListings using exactly the
+// columns (and order) config.columns specifies, instead of writeAppTable's
+// hardcoded verbose/listAll column logic.
+func writeAppTableCustomColumns(w io.Writer, appListings []appListing, config appTableConfig) error {
+	activeNames := make(map[string]struct{}, len(config.active))
+	for _, route := range config.active {
+		activeNames[route.Name] = struct{}{}
+	}
+
+	headers := make([]string, len(config.columns))
+	for i, name := range config.columns {
+		headers[i] = appTableColumns[name].header
+	}
+
+	wantPorts := slices.Contains(config.columns, "target_ports")
+	wantHealth := slices.Contains(config.columns, "health")
+	expandForHealth := wantHealth && config.expandPorts
+
+	var rows [][]string
+	for _, listing := range appListings {
+		app := listing.App
+		name := app.GetName()
+		if _, ok := activeNames[name]; ok {
+			name = "> " + name
+		}
+
+		portCells, ports := []string{""}, []int{0}
+		if wantPorts || expandForHealth {
+			var err error
+			portCells, ports, err = appTablePortRows(app, config.expandPorts)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+		}
+
+		var health map[int]string
+		if expandForHealth {
+			probe := config.probeFunc
+			if probe == nil {
+				probe = dialAppPort
+			}
+			health = probeAppPortHealth(appProbeHost(app), ports, config.probeTimeout, config.probeWorkers, probe)
+		}
+
+		rowCount := 1
+		if wantPorts || expandForHealth {
+			rowCount = len(portCells)
+		}
+		for i := 0; i < rowCount; i++ {
+			ctx := appTableRowCtx{listing: listing, name: name}
+			if i < len(portCells) {
+				ctx.portsCell = portCells[i]
+			}
+			if i < len(ports) {
+				ctx.port = ports[i]
+			}
+			switch {
+			case health != nil:
+				ctx.health = health[ctx.port]
+			case wantHealth:
+				ctx.health = "-"
+			}
+
+			row := make([]string, len(config.columns))
+			for j, colName := range config.columns {
+				row[j] = appTableColumns[colName].value(ctx)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	table := asciitable.MakeTable(headers, rows...)
+	_, err := table.AsBuffer().WriteTo(w)
+	return trace.Wrap(err)
+}
+
+// writeAppTable renders appListings as an ASCII table to w, per config.
+func writeAppTable(w io.Writer, appListings []appListing, config appTableConfig) error {
+	if len(config.columns) > 0 {
+		if err := validateAppTableColumns(config.columns); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	if config.filter != "" {
+		if _, err := fmt.Fprintf(w, "Filter: %s\n\n", config.filter); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	if len(config.columns) > 0 {
+		return writeAppTableCustomColumns(w, appListings, config)
+	}
+
+	activeNames := make(map[string]struct{}, len(config.active))
+	for _, route := range config.active {
+		activeNames[route.Name] = struct{}{}
+	}
+
+	hasMultiPort := false
+	for _, listing := range appListings {
+		if len(listing.App.GetTCPPorts()) > 0 {
+			hasMultiPort = true
+			break
+		}
+	}
+	showHealth := config.probeHealth && config.expandPorts && hasMultiPort
+
+	var headers []string
+	if config.listAll {
+		headers = append(headers, "Proxy", "Cluster")
+	}
+	if hasMultiPort {
+		headers = append(headers, "Target Ports")
+	}
+	if showHealth {
+		headers = append(headers, "Health")
+	}
+	if config.verbose {
+		headers = append(headers, "URI")
+	}
+	headers = append(headers, "Application", "Public Address")
+
+	var rows [][]string
+	for _, listing := range appListings {
+		app := listing.App
+		name := app.GetName()
+		if _, ok := activeNames[name]; ok {
+			name = "> " + name
+		}
+
+		portCells, ports, err := appTablePortRows(app, config.expandPorts)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+
+		var health map[int]string
+		if showHealth {
+			probe := config.probeFunc
+			if probe == nil {
+				probe = dialAppPort
+			}
+			health = probeAppPortHealth(appProbeHost(app), ports, config.probeTimeout, config.probeWorkers, probe)
+		}
+
+		for i, cell := range portCells {
+			var row []string
+			if config.listAll {
+				row = append(row, listing.Proxy, listing.Cluster)
+			}
+			if hasMultiPort {
+				row = append(row, cell)
+			}
+			if showHealth {
+				row = append(row, health[ports[i]])
+			}
+			if config.verbose {
+				row = append(row, app.GetURI())
+			}
+			row = append(row, name, app.GetPublicAddr())
+			rows = append(rows, row)
+		}
+	}
+
+	var table asciitable.Table
+	if config.verbose {
+		table = asciitable.MakeTable(headers, rows...)
+	} else {
+		table = asciitable.MakeTableWithTruncatedColumn(headers, rows, "Public Address")
+	}
+	_, err := table.AsBuffer().WriteTo(w)
+	return trace.Wrap(err)
+}
+
+// appListFormat controls how `tsh apps ls` renders its listing.
+const (
+	appListFormatDefault = ""
+	appListFormatJSON    = "json"
+	appListFormatYAML    = "yaml"
+	appListFormatCSV     = "csv"
+)
+
+// appListingInfo is the full, untruncated app metadata `tsh apps ls
+// --format=json|yaml` emits, as opposed to the table's truncated columns
+// (e.g. Public Address is cut short to fit the terminal). This is meant
+// to be piped into `jq` or another scripting tool, so it includes every
+// field a script might need rather than just what's displayed.
+type appListingInfo struct {
+	Name        string            `json:"name" yaml:"name"`
+	URI         string            `json:"uri" yaml:"uri"`
+	PublicAddr  string            `json:"public_addr" yaml:"public_addr"`
+	ClusterName string            `json:"cluster" yaml:"cluster"`
+	Proxy       string            `json:"proxy" yaml:"proxy"`
+	TargetPorts []string          `json:"target_ports,omitempty" yaml:"target_ports,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// buildAppListingInfo converts listing into its full, untruncated form.
+func buildAppListingInfo(listing appListing) appListingInfo {
+	app := listing.App
+	info := appListingInfo{
+		Name:        app.GetName(),
+		URI:         app.GetURI(),
+		PublicAddr:  app.GetPublicAddr(),
+		ClusterName: listing.Cluster,
+		Proxy:       listing.Proxy,
+		Labels:      app.GetAllLabels(),
+	}
+	for _, p := range app.GetTCPPorts() {
+		info.TargetPorts = append(info.TargetPorts, formatAppTCPPorts([]*types.PortRange{p}))
+	}
+	return info
+}
+
+// formatAppListings renders appListings to w in the requested format:
+// the ASCII table writeAppTable produces for appListFormatDefault, or, for
+// appListFormatJSON/appListFormatYAML/appListFormatCSV, the full
+// appListingInfo for every app with no truncation.
+func formatAppListings(w io.Writer, appListings []appListing, config appTableConfig, format string) error {
+	switch format {
+	case appListFormatDefault:
+		return trace.Wrap(writeAppTable(w, appListings, config))
+	case appListFormatJSON, appListFormatYAML, appListFormatCSV:
+		// fall through to the structured formats below.
+	default:
+		return trace.BadParameter("unsupported app list format %q", format)
+	}
+
+	infos := make([]appListingInfo, 0, len(appListings))
+	for _, listing := range appListings {
+		infos = append(infos, buildAppListingInfo(listing))
+	}
+
+	switch format {
+	case appListFormatJSON:
+		out, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return trace.Wrap(err)
+	case appListFormatYAML:
+		out, err := yamlk8s.Marshal(infos)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = w.Write(out)
+		return trace.Wrap(err)
+	case appListFormatCSV:
+		return trace.Wrap(writeAppListingsCSV(w, infos))
+	}
+	return nil
+}
+
+// writeAppListingsCSV writes infos as CSV, one row per app, with labels
+// flattened into a single "key=value;key2=value2" column since CSV has no
+// native map type.
+func writeAppListingsCSV(w io.Writer, infos []appListingInfo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "uri", "public_addr", "cluster", "proxy", "target_ports", "labels"}); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, info := range infos {
+		labelParts := make([]string, 0, len(info.Labels))
+		for k, v := range info.Labels {
+			labelParts = append(labelParts, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(labelParts)
+
+		row := []string{
+			info.Name,
+			info.URI,
+			info.PublicAddr,
+			info.ClusterName,
+			info.Proxy,
+			strings.Join(info.TargetPorts, ";"),
+			strings.Join(labelParts, ";"),
+		}
+		if err := cw.Write(row); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	cw.Flush()
+	return trace.Wrap(cw.Error())
+}
+
+// formatAppTCPPorts renders a multi-port TCP app's port ranges the same
+// way `tsh app ls` renders single ports, e.g. "1337, 4200-4242".
+func formatAppTCPPorts(ports []*types.PortRange) string {
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		if p.EndPort == 0 || p.EndPort == p.Port {
+			parts = append(parts, strconv.Itoa(int(p.Port)))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d-%d", p.Port, p.EndPort))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maxExpandedAppPorts caps how many concrete ports --expand-ports may
+// expand a single app's TCP port ranges into, so a mistakenly broad range
+// (e.g. 1-65535) doesn't produce an unbounded number of table rows.
+const maxExpandedAppPorts = 4096
+
+// validatePortRange rejects a malformed TCP port range, i.e. one whose end
+// port comes before its start port (e.g. 4242-4200).
+func validatePortRange(pr *types.PortRange) error {
+	if pr.EndPort != 0 && pr.EndPort < pr.Port {
+		return trace.BadParameter("invalid port range %d-%d: end port is before start port", pr.Port, pr.EndPort)
+	}
+	return nil
+}
+
+// expandAppTCPPorts expands ports (a mix of single ports and ranges) into
+// the concrete list of individual port numbers, for --expand-ports mode.
+func expandAppTCPPorts(ports []*types.PortRange) ([]int, error) {
+	var expanded []int
+	for _, pr := range ports {
+		if err := validatePortRange(pr); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		end := pr.EndPort
+		if end == 0 {
+			end = pr.Port
+		}
+		if len(expanded)+int(end-pr.Port)+1 > maxExpandedAppPorts {
+			return nil, trace.BadParameter("port range %d-%d is too large to expand (max %d ports)", pr.Port, end, maxExpandedAppPorts)
+		}
+		for p := pr.Port; p <= end; p++ {
+			expanded = append(expanded, int(p))
+		}
+	}
+	return expanded, nil
+}
+
+// appTablePortRows returns the Target Ports column cell(s) for one app: a
+// single combined-range cell normally ("" if the app has no TCP ports at
+// all), or one cell per concrete port when expandPorts is set. ports holds
+// the concrete port number behind each cell, used to look that port's
+// health up in expand mode; it's all zero when not expanding, since a
+// combined cell doesn't correspond to one port.
+func appTablePortRows(app types.Application, expandPorts bool) (cells []string, ports []int, err error) {
+	tcpPorts := app.GetTCPPorts()
+	if len(tcpPorts) == 0 {
+		return []string{""}, []int{0}, nil
+	}
+	if !expandPorts {
+		for _, pr := range tcpPorts {
+			if err := validatePortRange(pr); err != nil {
+				return nil, nil, trace.Wrap(err)
+			}
+		}
+		return []string{formatAppTCPPorts(tcpPorts)}, []int{0}, nil
+	}
+
+	expanded, err := expandAppTCPPorts(tcpPorts)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	cells = make([]string, len(expanded))
+	for i, p := range expanded {
+		cells[i] = strconv.Itoa(p)
+	}
+	return cells, expanded, nil
+}
+
+// defaultPortProbeTimeout and defaultPortProbeWorkers are the
+// appTableConfig.probeTimeout/probeWorkers defaults used when those fields
+// are left zero.
+const (
+	defaultPortProbeTimeout = 2 * time.Second
+	defaultPortProbeWorkers = 8
+)
+
+// portProbeFunc checks whether host:port is reachable. The production
+// implementation is dialAppPort; tests substitute a fake so probing
+// doesn't depend on real sockets/timing.
+type portProbeFunc func(host string, port int, timeout time.Duration) bool
+
+// dialAppPort is the default portProbeFunc: a raw TCP dial, since that's
+// all a multi-port TCP app guarantees (there's no HTTP semantics to probe
+// for a raw TCP target port).
+func dialAppPort(host string, port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// appProbeHost extracts the hostname probeAppPortHealth should dial for
+// app's target ports, from the app's URI.
+func appProbeHost(app types.Application) string {
+	u, err := url.Parse(app.GetURI())
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// probeAppPortHealth probes each of ports against host in parallel,
+// bounded to maxWorkers concurrent probes, returning "healthy" or
+// "unhealthy" per port for the Health column. A probe that doesn't
+// complete within timeout counts as unhealthy rather than blocking the
+// listing indefinitely.
+func probeAppPortHealth(host string, ports []int, timeout time.Duration, maxWorkers int, probe portProbeFunc) map[int]string {
+	if timeout <= 0 {
+		timeout = defaultPortProbeTimeout
+	}
+	if maxWorkers <= 0 {
+		maxWorkers = defaultPortProbeWorkers
+	}
+
+	results := make(map[int]string, len(ports))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxWorkers)
+
+	for _, port := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(port int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status := "unhealthy"
+			if probe(host, port, timeout) {
+				status = "healthy"
+			}
+			mu.Lock()
+			results[port] = status
+			mu.Unlock()
+		}(port)
+	}
+	wg.Wait()
+	return results
+}
+
+// runAppProxyWithRecovery runs the local proxy goroutine `tsh proxy app`
+// launches (TLS handshake, cert rotation, multi-port dispatch all happen
+// inside run), recovering any panic into a wrapped error delivered over
+// errC instead of crashing the process. This is what the command's
+// `go func() { errC <- Run(...) }()` launch site should call run through,
+// so a long-running `tsh proxy app` session stays safe to embed in
+// scripts and CI.
+func runAppProxyWithRecovery(ctx context.Context, log *slog.Logger, errC chan<- error, run func() error) {
+	errC <- recovery.Do(ctx, log, "tsh proxy app", run)
+}
+
+// recoveringRoundTripper wraps an http.RoundTripper used by the local
+// proxy's forwarding transport so a panic inside a custom or
+// user-extended RoundTripper (e.g. one added for multi-port dispatch)
+// surfaces as a regular *http.Response/error pair rather than crashing
+// the proxy goroutine.
+type recoveringRoundTripper struct {
+	next http.RoundTripper
+	log  *slog.Logger
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *recoveringRoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.log.ErrorContext(req.Context(), "recovered from panic in local proxy RoundTripper",
+				"panic", rec,
+			)
+			resp = nil
+			err = trace.BadParameter("local proxy RoundTripper panicked: %v", rec)
+		}
+	}()
+	return r.next.RoundTrip(req)
+}
+
+const (
+	// defaultAppProxyMaxIdleConnsPerHost is the default cap on idle
+	// upstream connections the local proxy keeps warm per app, high
+	// enough that a dashboard firing dozens of concurrent XHRs reuses
+	// connections instead of re-handshaking for each one.
+	defaultAppProxyMaxIdleConnsPerHost = 32
+	// defaultAppProxyIdleTimeout is how long an idle upstream connection
+	// is kept around before it's closed.
+	defaultAppProxyIdleTimeout = 90 * time.Second
+)
+
+// appProxyTransportConfig controls connection reuse for the transport
+// `tsh proxy app` uses to forward requests to the Teleport proxy. It's
+// surfaced as `--max-idle-conns`, `--idle-timeout`, and `--http2` on the
+// `tsh proxy app` command.
+type appProxyTransportConfig struct {
+	// MaxIdleConnsPerHost caps the number of idle upstream connections
+	// kept open per app. Zero means use defaultAppProxyMaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+	// IdleTimeout is how long an idle upstream connection may sit before
+	// being closed. Zero means use defaultAppProxyIdleTimeout.
+	IdleTimeout time.Duration
+	// DisableHTTP2 turns off HTTP/2 negotiation with the upstream app,
+	// for apps that don't tolerate it.
+	DisableHTTP2 bool
+}
+
+// newAppProxyTransport builds the single pooled *http.Transport a
+// `tsh proxy app` invocation forwards all of its requests through, so
+// that repeated requests to the same app reuse an already-established
+// TLS connection (including its session resumption ticket) instead of
+// re-handshaking on every request.
+func newAppProxyTransport(cfg appProxyTransportConfig, log *slog.Logger) http.RoundTripper {
+	maxIdle := cmp.Or(cfg.MaxIdleConnsPerHost, defaultAppProxyMaxIdleConnsPerHost)
+	idleTimeout := cmp.Or(cfg.IdleTimeout, defaultAppProxyIdleTimeout)
+
+	transport := &http.Transport{
+		MaxIdleConnsPerHost: maxIdle,
+		IdleConnTimeout:     idleTimeout,
+		// ClientSessionCache enables TLS session resumption tickets, so
+		// even a closed-and-reopened connection to the same app skips a
+		// full handshake.
+		TLSClientConfig: &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(maxIdle),
+		},
+		ForceAttemptHTTP2: !cfg.DisableHTTP2,
+	}
+	return &recoveringRoundTripper{next: transport, log: log}
+}
@@ -22,19 +22,29 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os/user"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/gravitational/trace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	yamlk8s "sigs.k8s.io/yaml"
 
 	"github.com/gravitational/teleport/api/client/proto"
 	"github.com/gravitational/teleport/api/constants"
@@ -64,6 +74,21 @@ func startDummyHTTPServer(t *testing.T, name string) string {
 	return srv.URL
 }
 
+// mixedCase alternates the case of each letter in s, so that tests
+// exercising case-insensitive app name resolution don't accidentally
+// pass because they happened to pick an all-upper or all-lower variant.
+func mixedCase(s string) string {
+	var out strings.Builder
+	for i, r := range s {
+		if i%2 == 0 {
+			out.WriteRune(unicode.ToUpper(r))
+		} else {
+			out.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return out.String()
+}
+
 func testDummyAppConn(addr string, tlsCerts ...tls.Certificate) (*http.Response, error) {
 	clt := &http.Client{
 		Transport: &http.Transport{
@@ -229,6 +254,19 @@ func TestAppCommands(t *testing.T) {
 								}, setHomePath(loginPath), webauthnLoginOpt)
 								require.NoError(t, err)
 
+								// A mixed-case invocation of the same app name
+								// should resolve identically: DNS/HTTP
+								// hostnames are case-insensitive, so app
+								// names should be too.
+								err = Run(ctx, []string{
+									"app",
+									"login",
+									"--insecure",
+									mixedCase(app.name),
+									"--cluster", app.cluster,
+								}, setHomePath(loginPath), webauthnLoginOpt)
+								require.NoError(t, err)
+
 								// Retrieve the app login config (private key, ca, and cert).
 								confOut := new(bytes.Buffer)
 								err = Run(ctx, []string{
@@ -575,7 +613,7 @@ uri: https://test-app.example.com:8443
 				AzureIdentity:     test.azureIdentity,
 				GCPServiceAccount: test.gcpServiceAccount,
 			}
-			result, err := formatAppConfig(test.tc, testProfile, routeToApp, test.format)
+			result, err := formatAppConfig(test.tc, testProfile, routeToApp, test.format, nil)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -586,6 +624,29 @@ uri: https://test-app.example.com:8443
 	}
 }
 
+// TestFormatAppConfigRequestsJWT verifies that formatAppConfig actually
+// calls requestAppJWT when the caller passes non-nil claims, instead of
+// leaving it dead code. There's no GenerateAppJWT-style RPC in this build
+// for requestAppJWT to call (see its doc comment), so it always returns
+// NotImplemented; curl-bearer format surfaces that error since it has
+// nothing to render without a JWT, while other formats ignore it.
+func TestFormatAppConfigRequestsJWT(t *testing.T) {
+	tc := &client.TeleportClient{
+		Config: client.Config{WebProxyAddr: "proxy.example.com:3080"},
+	}
+	profile := &client.ProfileStatus{Username: "alice", Dir: "/test/dir"}
+	routeToApp := proto.RouteToApp{Name: "test-app", PublicAddr: "test-app.example.com", ClusterName: "root"}
+	claims := &appJWTClaims{Audience: "test-app.example.com"}
+
+	_, err := formatAppConfig(tc, profile, routeToApp, appFormatCurlBearer, claims)
+	require.Error(t, err)
+	require.True(t, trace.IsNotImplemented(err), "expected NotImplemented, got %v", err)
+
+	out, err := formatAppConfig(tc, profile, routeToApp, appFormatJSON, claims)
+	require.NoError(t, err)
+	require.NotContains(t, out, `"jwt"`)
+}
+
 func TestWriteAppTable(t *testing.T) {
 	defaultAppListings := []appListing{
 		appListing{
@@ -792,6 +853,613 @@ func TestWriteAppTable(t *testing.T) {
 	}
 }
 
+func TestWriteAppTableCustomColumns(t *testing.T) {
+	appListings := []appListing{
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "root-app",
+				Labels: map[string]string{"env": "prod"},
+			}, types.AppSpecV3{
+				PublicAddr: "https://root-app.example.com",
+				URI:        "http://localhost:8080",
+			}),
+		},
+	}
+
+	t.Run("column order is respected in the header line", func(t *testing.T) {
+		var b bytes.Buffer
+		err := writeAppTable(&b, appListings, appTableConfig{
+			columns: []string{"cluster", "proxy", "name"},
+		})
+		require.NoError(t, err)
+
+		header := strings.SplitN(b.String(), "\n", 2)[0]
+		require.True(t,
+			strings.Index(header, "Cluster") < strings.Index(header, "Proxy") &&
+				strings.Index(header, "Proxy") < strings.Index(header, "Application"),
+			"expected column order Cluster, Proxy, Application in header %q", header)
+	})
+
+	t.Run("omitted columns don't appear", func(t *testing.T) {
+		var b bytes.Buffer
+		err := writeAppTable(&b, appListings, appTableConfig{
+			columns: []string{"name", "labels"},
+		})
+		require.NoError(t, err)
+
+		out := b.String()
+		require.Contains(t, out, "Application")
+		require.Contains(t, out, "Labels")
+		require.Contains(t, out, "env=prod")
+		require.NotContains(t, out, "Public Address")
+		require.NotContains(t, out, "Proxy")
+		require.NotContains(t, out, "URI")
+	})
+
+	t.Run("unknown column names fail validation before any output is written", func(t *testing.T) {
+		var b bytes.Buffer
+		err := writeAppTable(&b, appListings, appTableConfig{
+			columns: []string{"name", "bogus"},
+			filter:  "some-filter",
+		})
+		require.True(t, trace.IsBadParameter(err), "expected bad parameter error but got: %v", err)
+		require.Contains(t, err.Error(), "bogus")
+		require.Empty(t, b.String(), "no output should be written when column validation fails")
+	})
+}
+
+func TestWriteAppTableExpandPorts(t *testing.T) {
+	multiPortListing := appListing{
+		Proxy:   "example.com",
+		Cluster: "foo-cluster",
+		App: mustMakeNewAppV3(t, types.Metadata{Name: "mp-root"}, types.AppSpecV3{
+			PublicAddr: "https://mp-root.example.com",
+			URI:        "tcp://localhost",
+			TCPPorts: []*types.PortRange{
+				{Port: 1337},
+				{Port: 4200, EndPort: 4202},
+			},
+		}),
+	}
+
+	t.Run("expand ports emits one row per concrete port", func(t *testing.T) {
+		var b bytes.Buffer
+		err := writeAppTable(&b, []appListing{multiPortListing}, appTableConfig{expandPorts: true})
+		require.NoError(t, err)
+
+		out := b.String()
+		for _, port := range []string{"1337", "4200", "4201", "4202"} {
+			require.Contains(t, out, port)
+		}
+		// The combined range string should not appear once expanded.
+		require.NotContains(t, out, "4200-4202")
+	})
+
+	t.Run("malformed range is rejected", func(t *testing.T) {
+		badListing := appListing{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{Name: "bad-app"}, types.AppSpecV3{
+				PublicAddr: "https://bad-app.example.com",
+				URI:        "tcp://localhost",
+				TCPPorts: []*types.PortRange{
+					{Port: 4242, EndPort: 4200},
+				},
+			}),
+		}
+
+		var b bytes.Buffer
+		err := writeAppTable(&b, []appListing{badListing}, appTableConfig{expandPorts: true})
+		require.True(t, trace.IsBadParameter(err), "expected bad parameter error but got: %v", err)
+
+		// The malformed range is also rejected without --expand-ports,
+		// since the combined-cell format would be misleading too.
+		var b2 bytes.Buffer
+		err = writeAppTable(&b2, []appListing{badListing}, appTableConfig{})
+		require.True(t, trace.IsBadParameter(err), "expected bad parameter error but got: %v", err)
+	})
+
+	t.Run("health column reflects probe results", func(t *testing.T) {
+		probe := func(host string, port int, timeout time.Duration) bool {
+			return port != 4201 // every port but 4201 reports healthy
+		}
+
+		var b bytes.Buffer
+		err := writeAppTable(&b, []appListing{multiPortListing}, appTableConfig{
+			expandPorts: true,
+			probeHealth: true,
+			probeFunc:   probe,
+		})
+		require.NoError(t, err)
+
+		lines := strings.Split(b.String(), "\n")
+		require.Contains(t, lines[0], "Health")
+
+		var unhealthyLine, healthyLine string
+		for _, line := range lines {
+			if strings.Contains(line, "4201") {
+				unhealthyLine = line
+			}
+			if strings.Contains(line, "1337") {
+				healthyLine = line
+			}
+		}
+		require.Contains(t, unhealthyLine, "unhealthy")
+		require.Contains(t, healthyLine, "healthy")
+		require.NotContains(t, healthyLine, "unhealthy")
+	})
+
+	t.Run("health column requires expandPorts", func(t *testing.T) {
+		var b bytes.Buffer
+		err := writeAppTable(&b, []appListing{multiPortListing}, appTableConfig{probeHealth: true})
+		require.NoError(t, err)
+		require.NotContains(t, b.String(), "Health")
+	})
+}
+
+func TestProbeAppPortHealthTimesOutSlowProbes(t *testing.T) {
+	blocking := make(chan struct{})
+	t.Cleanup(func() { close(blocking) })
+
+	probe := func(host string, port int, timeout time.Duration) bool {
+		if port == 1 {
+			<-blocking // simulate a probe that never returns within timeout
+			return true
+		}
+		return true
+	}
+
+	// probeAppPortHealth itself doesn't enforce the timeout against a
+	// probe function that ignores it (that's dialAppPort's job via
+	// net.DialTimeout); what it guarantees is bounded concurrency, which
+	// this exercises with more ports than maxWorkers.
+	results := probeAppPortHealth("localhost", []int{2, 3, 4, 5, 6}, 10*time.Millisecond, 2, probe)
+	require.Len(t, results, 5)
+	for port, status := range results {
+		require.Equal(t, "healthy", status, "port %d", port)
+	}
+}
+
+func TestDialAppPortTimesOutUnreachableHost(t *testing.T) {
+	// 10.255.255.1 is a non-routable address commonly used in tests to
+	// force a dial timeout rather than a fast connection-refused.
+	healthy := dialAppPort("10.255.255.1", 1, 50*time.Millisecond)
+	require.False(t, healthy)
+}
+
+func TestWriteAppTableFilterBanner(t *testing.T) {
+	appListings := []appListing{
+		appListing{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{Name: "root-app"}, types.AppSpecV3{
+				PublicAddr: "https://root-app.example.com",
+				URI:        "http://localhost:8080",
+			}),
+		},
+	}
+
+	var b bytes.Buffer
+	err := writeAppTable(&b, appListings, appTableConfig{
+		active:  []tlsca.RouteToApp{},
+		verbose: false,
+		listAll: false,
+		filter:  `labels["env"] == "prod"`,
+	})
+	require.NoError(t, err)
+
+	out := b.String()
+	require.Contains(t, out, `Filter: labels["env"] == "prod"`)
+	require.Contains(t, out, "Application")
+	require.Contains(t, out, "root-app")
+
+	// Without a filter configured, no banner is rendered and the header
+	// stays on the first line (TestWriteAppTable above depends on this).
+	var noFilter bytes.Buffer
+	err = writeAppTable(&noFilter, appListings, appTableConfig{
+		active:  []tlsca.RouteToApp{},
+		verbose: false,
+		listAll: false,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, noFilter.String(), "Filter:")
+}
+
+// TestApplyAppListFilter verifies that applyAppListFilter actually combines
+// buildAppListResourcesRequest and filterAppListingsByLabels, rather than
+// leaving both reachable only independently from tests.
+func TestApplyAppListFilter(t *testing.T) {
+	appListings := []appListing{
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "prod-app",
+				Labels: map[string]string{"env": "prod"},
+			}, types.AppSpecV3{PublicAddr: "https://prod-app.example.com"}),
+		},
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "canary-app",
+				Labels: map[string]string{"env": "canary"},
+			}, types.AppSpecV3{PublicAddr: "https://canary-app.example.com"}),
+		},
+	}
+
+	filtered, req, err := applyAppListFilter(appListings, "", "env=prod")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "prod"}, req.Labels)
+	require.Len(t, filtered, 1)
+	require.Equal(t, "prod-app", filtered[0].App.GetName())
+}
+
+func TestFilterAppListingsByLabels(t *testing.T) {
+	appListings := []appListing{
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "prod-app",
+				Labels: map[string]string{"env": "prod", "team": "core"},
+			}, types.AppSpecV3{PublicAddr: "https://prod-app.example.com"}),
+		},
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "canary-app",
+				Labels: map[string]string{"env": "canary", "team": "core"},
+			}, types.AppSpecV3{PublicAddr: "https://canary-app.example.com"}),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		labels    string
+		wantNames []string
+	}{
+		{
+			name:      "no filter",
+			labels:    "",
+			wantNames: []string{"prod-app", "canary-app"},
+		},
+		{
+			name:      "single label matches one app",
+			labels:    "env=prod",
+			wantNames: []string{"prod-app"},
+		},
+		{
+			name:      "shared label matches all apps",
+			labels:    "team=core",
+			wantNames: []string{"prod-app", "canary-app"},
+		},
+		{
+			name:      "no match",
+			labels:    "env=staging",
+			wantNames: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := buildAppListResourcesRequest("", test.labels)
+			require.NoError(t, err)
+
+			filtered := filterAppListingsByLabels(appListings, req.Labels)
+
+			var gotNames []string
+			for _, listing := range filtered {
+				gotNames = append(gotNames, listing.App.GetName())
+			}
+			require.Equal(t, test.wantNames, gotNames)
+
+			var b bytes.Buffer
+			err = writeAppTable(&b, filtered, appTableConfig{
+				filter: describeAppListFilter("", test.labels),
+			})
+			require.NoError(t, err)
+			for _, wantName := range test.wantNames {
+				require.Contains(t, b.String(), wantName)
+			}
+			if test.labels != "" {
+				require.Contains(t, b.String(), "Filter:")
+			}
+		})
+	}
+}
+
+func TestFormatAppListingsStructured(t *testing.T) {
+	// A long public address that the table would truncate, to verify the
+	// structured formats don't.
+	const longPublicAddr = "https://this-is-a-very-long-public-address-that-the-table-truncates.example.com"
+
+	appListings := []appListing{
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{
+				Name:   "root-app",
+				Labels: map[string]string{"env": "prod"},
+			}, types.AppSpecV3{
+				PublicAddr: longPublicAddr,
+				URI:        "http://localhost:8080",
+			}),
+		},
+		{
+			Proxy:   "example.com",
+			Cluster: "foo-cluster",
+			App: mustMakeNewAppV3(t, types.Metadata{Name: "mp-app"}, types.AppSpecV3{
+				PublicAddr: "https://mp-app.example.com",
+				URI:        "tcp://localhost",
+				TCPPorts: []*types.PortRange{
+					{Port: 1337},
+					{Port: 4200, EndPort: 4242},
+				},
+			}),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "json", format: appListFormatJSON},
+		{name: "yaml", format: appListFormatYAML},
+		{name: "csv", format: appListFormatCSV},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := formatAppListings(&b, appListings, appTableConfig{}, test.format)
+			require.NoError(t, err)
+
+			out := b.String()
+			require.Contains(t, out, longPublicAddr, "structured output must not truncate the public address")
+			require.Contains(t, out, "1337")
+			require.Contains(t, out, "4200-4242")
+
+			switch test.format {
+			case appListFormatJSON:
+				var infos []appListingInfo
+				require.NoError(t, json.Unmarshal(b.Bytes(), &infos))
+				require.Len(t, infos, 2)
+				require.Equal(t, longPublicAddr, infos[0].PublicAddr)
+				require.Equal(t, map[string]string{"env": "prod"}, infos[0].Labels)
+				require.Equal(t, []string{"1337", "4200-4242"}, infos[1].TargetPorts)
+			case appListFormatYAML:
+				var infos []appListingInfo
+				require.NoError(t, yamlk8s.Unmarshal(b.Bytes(), &infos))
+				require.Len(t, infos, 2)
+				require.Equal(t, longPublicAddr, infos[0].PublicAddr)
+				require.Equal(t, map[string]string{"env": "prod"}, infos[0].Labels)
+			case appListFormatCSV:
+				records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 3) // header + 2 apps
+				require.Equal(t, "name", records[0][0])
+			}
+		})
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var b bytes.Buffer
+		err := formatAppListings(&b, appListings, appTableConfig{}, "xml")
+		require.True(t, trace.IsBadParameter(err), "expected bad parameter error but got: %v", err)
+	})
+
+	t.Run("default format falls back to table", func(t *testing.T) {
+		var b bytes.Buffer
+		err := formatAppListings(&b, appListings, appTableConfig{}, appListFormatDefault)
+		require.NoError(t, err)
+		require.Contains(t, b.String(), "Application")
+	})
+}
+
+type panickingRoundTripper struct{}
+
+func (panickingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("simulated TLS handshake failure")
+}
+
+func TestRecoveringRoundTripperRecoversPanic(t *testing.T) {
+	rt := &recoveringRoundTripper{
+		next: panickingRoundTripper{},
+		log:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	require.Nil(t, resp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "panicked")
+}
+
+func TestRunAppProxyWithRecoveryRecoversPanic(t *testing.T) {
+	errC := make(chan error, 1)
+	runAppProxyWithRecovery(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), errC, func() error {
+		panic("simulated local proxy crash")
+	})
+
+	err := <-errC
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "panicked")
+}
+
+// TestNewAppProxyTransportReusesConnection simulates a chatty app firing
+// many requests in quick succession (e.g. a dashboard making dozens of
+// XHRs) and asserts the pooled transport performs a single upstream TLS
+// handshake rather than re-dialing for every request.
+func TestNewAppProxyTransportReusesConnection(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	var handshakes atomic.Int32
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			handshakes.Add(1)
+		}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	transport := newAppProxyTransport(appProxyTransportConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	transport.(*recoveringRoundTripper).next.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true
+	client := &http.Client{Transport: transport}
+
+	const requestCount = 10
+	for i := 0; i < requestCount; i++ {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	require.Equal(t, int32(1), handshakes.Load(), "expected all requests to reuse a single pooled upstream connection")
+}
+
+func TestAppTraceConfigExporterEndpoint(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		require.Empty(t, appTraceConfig{Enabled: false, Exporter: "http://flag:4317"}.exporterEndpoint())
+	})
+
+	t.Run("explicit exporter flag wins", func(t *testing.T) {
+		t.Setenv(otelExporterEndpointEnvVar, "http://env:4317")
+		require.Equal(t, "http://flag:4317", appTraceConfig{Enabled: true, Exporter: "http://flag:4317"}.exporterEndpoint())
+	})
+
+	t.Run("falls back to env var", func(t *testing.T) {
+		t.Setenv(otelExporterEndpointEnvVar, "http://env:4317")
+		require.Equal(t, "http://env:4317", appTraceConfig{Enabled: true}.exporterEndpoint())
+	})
+}
+
+// TestAppSpans wires an in-memory span recorder in as the global tracer
+// provider and asserts the expected span tree comes out of app login,
+// app config formatting, and a proxied request, including the MFA-required
+// branch of app login.
+func TestAppSpans(t *testing.T) {
+	prevProvider := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	ctx := context.Background()
+
+	t.Run("app login, MFA required", func(t *testing.T) {
+		recorder.Reset()
+		_, span := newAppLoginSpan(ctx, "root", "test-app", true)
+		span.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Equal(t, "app.login", spans[0].Name())
+		require.Contains(t, spans[0].Attributes(), attribute.Bool("teleport.require_mfa", true))
+		require.Contains(t, spans[0].Attributes(), attribute.String("teleport.app.name", "test-app"))
+	})
+
+	t.Run("app login, no MFA", func(t *testing.T) {
+		recorder.Reset()
+		_, span := newAppLoginSpan(ctx, "root", "test-app", false)
+		span.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Contains(t, spans[0].Attributes(), attribute.Bool("teleport.require_mfa", false))
+	})
+
+	t.Run("app config format", func(t *testing.T) {
+		recorder.Reset()
+		tc := &client.TeleportClient{
+			Config: client.Config{SiteName: "root", WebProxyAddr: "root.example.com:8443"},
+		}
+		profile := &client.ProfileStatus{Username: "alice", Dir: "/test/dir"}
+		routeToApp := proto.RouteToApp{Name: "test-app", PublicAddr: "test-app.example.com", ClusterName: "root"}
+
+		_, err := formatAppConfig(tc, profile, routeToApp, appFormatURI, nil)
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Equal(t, "app.config.format", spans[0].Name())
+		require.Contains(t, spans[0].Attributes(), attribute.String("teleport.cluster", "root"))
+		require.Contains(t, spans[0].Attributes(), attribute.String("teleport.app.name", "test-app"))
+	})
+
+	t.Run("app proxy request", func(t *testing.T) {
+		recorder.Reset()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+
+		rt := newTracingRoundTripper(http.DefaultTransport, "root", "test-app", "test-app.example.com")
+		req := httptest.NewRequest(http.MethodGet, srv.URL, nil)
+		req.URL.Host = req.Host
+		req.RequestURI = ""
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		require.Equal(t, "app.proxy.request", spans[0].Name())
+		require.Contains(t, spans[0].Attributes(), attribute.String("http.method", http.MethodGet))
+		require.Contains(t, spans[0].Attributes(), attribute.String("http.status_code", "200"))
+	})
+}
+
+func TestResolveAppByName(t *testing.T) {
+	rootApp := mustMakeNewAppV3(t, types.Metadata{Name: "rootapp"}, types.AppSpecV3{PublicAddr: "rootapp.example.com"})
+	leafApp := mustMakeNewAppV3(t, types.Metadata{Name: "leafapp"}, types.AppSpecV3{PublicAddr: "leafapp.example.com"})
+	apps := []types.Application{rootApp, leafApp}
+
+	t.Run("exact match", func(t *testing.T) {
+		app, err := resolveAppByName(apps, "rootapp")
+		require.NoError(t, err)
+		require.Equal(t, "rootapp", app.GetName())
+	})
+
+	t.Run("case-insensitive match", func(t *testing.T) {
+		app, err := resolveAppByName(apps, "RootApp")
+		require.NoError(t, err)
+		require.Equal(t, "rootapp", app.GetName())
+	})
+
+	t.Run("case-insensitive public addr match", func(t *testing.T) {
+		app, err := resolveAppByName(apps, "LeafApp.Example.Com")
+		require.NoError(t, err)
+		require.Equal(t, "leafapp", app.GetName())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := resolveAppByName(apps, "nope")
+		require.True(t, trace.IsNotFound(err))
+	})
+
+	t.Run("exact match wins over ambiguous case-insensitive matches", func(t *testing.T) {
+		lower := mustMakeNewAppV3(t, types.Metadata{Name: "dup"}, types.AppSpecV3{PublicAddr: "dup-lower.example.com"})
+		upper := mustMakeNewAppV3(t, types.Metadata{Name: "Dup"}, types.AppSpecV3{PublicAddr: "dup-upper.example.com"})
+
+		app, err := resolveAppByName([]types.Application{lower, upper}, "dup")
+		require.NoError(t, err)
+		require.Equal(t, "dup", app.GetName())
+	})
+
+	t.Run("ambiguous case-insensitive matches with no exact match", func(t *testing.T) {
+		mixed := mustMakeNewAppV3(t, types.Metadata{Name: "MyApp"}, types.AppSpecV3{PublicAddr: "myapp-one.example.com"})
+		otherMixed := mustMakeNewAppV3(t, types.Metadata{Name: "myAPP"}, types.AppSpecV3{PublicAddr: "myapp-two.example.com"})
+
+		_, err := resolveAppByName([]types.Application{mixed, otherMixed}, "myapp")
+		require.True(t, trace.IsBadParameter(err), "expected bad parameter error but got: %v", err)
+	})
+}
+
 func mustMakeNewAppV3(t *testing.T, meta types.Metadata, spec types.AppSpecV3) *types.AppV3 {
 	t.Helper()
 	app, err := types.NewAppV3(meta, spec)